@@ -0,0 +1,143 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mrsobakin/itmournament/internal/docker"
+)
+
+// Packs the directory into a tar archive, skipping the git directory.
+func tarDir(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		if !d.Type().IsRegular() && !d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		_, err = tw.Write(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Serves the directory as a tar archive over loopback, so that the
+// builder can fetch it the same way it fetches uploaded archives.
+//
+// This relies on the builder sharing the network with the host,
+// which is the case for the builder built into the docker daemon.
+func serveDir(dir string) (string, func(), error) {
+	archive, err := tarDir(dir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/x-tar")
+			w.Write(archive)
+		}),
+	}
+	go srv.Serve(listener)
+
+	url := fmt.Sprintf("http://%s/src.tar", listener.Addr())
+
+	return url, func() { srv.Close() }, nil
+}
+
+func runBuild(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	ref := flags.String("ref", "main", "git ref to build, repo only")
+	args = parseFlags(flags, args, "<repo|dir>")
+
+	var src docker.Source
+
+	if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+		url, stop, err := serveDir(args[0])
+		if err != nil {
+			fail(err)
+		}
+		defer stop()
+
+		src.Src = url
+	} else {
+		src.Repo = strings.TrimPrefix(args[0], "https://github.com/")
+		src.Ref = *ref
+	}
+
+	builder, err := docker.NewSubmissionBuilder(newDockerClient(), ctx, os.Getenv("GIT_AUTH_TOKEN"))
+	if err != nil {
+		fail(err)
+	}
+
+	result := builder.Build(ctx, src)
+
+	fmt.Fprint(os.Stderr, result.Logs)
+
+	if result.Err != nil {
+		fail(result.Err)
+	}
+
+	fmt.Println(result.ImageId)
+}
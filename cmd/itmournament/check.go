@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mrsobakin/itmournament/internal/conformance"
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+func runCheck(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	players := &playerFlags{
+		judge:  &judge.Judge{},
+		limits: limitFlags(flags),
+	}
+	timeout := flags.Duration("timeout", 2*time.Minute, "time limit of a player in a single check")
+	asJson := flags.Bool("json", false, "print the report as json")
+	args = parseFlags(flags, args, "<player>")
+
+	report := conformance.Run(ctx, players.factory(args[0]), *timeout)
+	if ctx.Err() != nil {
+		fail(context.Cause(ctx))
+	}
+
+	if *asJson {
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			fail(err)
+		}
+	} else {
+		for _, check := range report.Checks {
+			if check.Passed {
+				fmt.Printf("PASS %s\n", check.Name)
+			} else if check.Optional {
+				fmt.Printf("WARN %s (optional): %s\n", check.Name, check.Details)
+			} else {
+				fmt.Printf("FAIL %s: %s\n", check.Name, check.Details)
+				if check.Field != "" {
+					fmt.Printf("dumped field:\n%s", check.Field)
+				}
+			}
+		}
+	}
+
+	if !report.Passed {
+		os.Exit(1)
+	}
+}
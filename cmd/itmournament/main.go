@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/docker/docker/client"
+
+	"github.com/mrsobakin/itmournament/internal/docker"
+)
+
+// Exit codes for match results are the values of `judge.Result`,
+// i.e. 0 for tie, 1 if the master won and 2 if the slave won.
+const exitFailure = 3
+
+const usage = `usage: itmournament <command> [flags] [args]
+
+commands:
+  build <repo|dir>   build a submission from a github repo or a local directory
+  match <a> <b>      judge a single match, a as master and b as slave
+  series <a> <b>     judge a series of matches, alternating roles
+  check <player>     check that the player follows the protocol
+
+Players are either local executables, docker image ids or built-in
+bots: bot:random, bot:hunt-target, bot:parity and bot:density.
+
+Exit code of match and series is 0 on tie, 1 if the first player
+won, 2 if the second player won and 3 on failure. Exit code of
+check is 0 if all required checks have passed and 1 otherwise.
+`
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(exitFailure)
+}
+
+// Parses flags of a subcommand, exiting on failure. Flag package
+// exits with 2 on its own, which would be confused with a verdict.
+func parseFlags(fs *flag.FlagSet, args []string, argNames ...string) []string {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: itmournament %s [flags] %s\n", fs.Name(), strings.Join(argNames, " "))
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(exitFailure)
+	}
+
+	if fs.NArg() != len(argNames) {
+		fs.Usage()
+		os.Exit(exitFailure)
+	}
+
+	return fs.Args()
+}
+
+func newDockerClient() *client.Client {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		fail(err)
+	}
+	return cli
+}
+
+func limitFlags(fs *flag.FlagSet) func() docker.Limits {
+	memory := fs.Int64("memory", 70, "player memory limit in MiB, docker only")
+	vcpus := fs.Float64("vcpus", 1, "player cpu limit, docker only")
+
+	return func() docker.Limits {
+		return docker.Limits{
+			Memory: *memory * 1024 * 1024,
+			VCPUs:  *vcpus,
+		}
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(exitFailure)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	command, args := os.Args[1], os.Args[2:]
+
+	switch command {
+	case "build":
+		runBuild(ctx, args)
+	case "match":
+		runMatch(ctx, args)
+	case "series":
+		runSeries(ctx, args)
+	case "check":
+		runCheck(ctx, args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(exitFailure)
+	}
+}
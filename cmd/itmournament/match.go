@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrsobakin/itmournament/internal/bots"
+	"github.com/mrsobakin/itmournament/internal/docker"
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/process"
+)
+
+type playerFlags struct {
+	judge  *judge.Judge
+	confs  []field.Configuration
+	limits func() docker.Limits
+	runner *docker.SubmissionRunner
+}
+
+func newPlayerFlags(fs *flag.FlagSet) *playerFlags {
+	f := &playerFlags{
+		judge:  &judge.Judge{},
+		limits: limitFlags(fs),
+	}
+
+	fs.DurationVar(&f.judge.PlayerTimeout, "player-timeout", 2*time.Minute, "time limit of a single player")
+	fs.DurationVar(&f.judge.GlobalTimeout, "global-timeout", 7*time.Minute, "time limit of a whole match")
+	fs.Int64Var(&f.judge.MaxShots, "max-shots", 0, "shot limit of a round, derived from the field size if 0")
+	fs.IntVar(&f.judge.MaxShipSize, "max-ship-size", field.DefaultMaxShipSize, "largest ship size players are asked and told about")
+	fs.Func("rules", "rule set to judge by, one of: "+strings.Join(judge.RulesNames(), ", "), func(name string) error {
+		rules, err := judge.LookupRules(name)
+		f.judge.Rules = rules
+		return err
+	})
+	fs.Func("breaker-shooter", "how the judge shoots in the breaker round, one of: "+strings.Join(judge.BreakerShooterNames(), ", "), func(name string) error {
+		shooter, err := judge.LookupBreakerShooter(name)
+		f.judge.BreakerShooter = shooter
+		return err
+	})
+	fs.Int64Var(&f.judge.BreakerShots, "breaker-shots", 0, "shots the judge fires in the breaker round, unlimited if 0")
+
+	fs.Func("configuration", "configuration chosen by the judge instead of the master, e.g. 10x10:4,3,2,1.\nIf repeated, one of them is sampled for every match", func(s string) error {
+		conf, err := field.ParseConfiguration(s)
+		if err != nil {
+			return err
+		}
+		f.confs = append(f.confs, conf)
+		f.judge.Configurations = judge.SampledConfiguration(f.confs...)
+		return nil
+	})
+	fs.Func("fleet-seed", "if not 0, the judge places ships of both players itself, generating\nthe same fleets for the same seed. Requires -configuration", func(s string) error {
+		seed, err := strconv.ParseUint(s, 10, 64)
+		if seed != 0 {
+			f.judge.Placements = bots.Placements(seed)
+		}
+		return err
+	})
+
+	return f
+}
+
+// Fails on flags that make no sense together, once they are all parsed.
+func (f *playerFlags) check() {
+	for _, conf := range f.confs {
+		if err := f.judge.CheckConfiguration(conf); err != nil {
+			fail(err)
+		}
+	}
+	if f.judge.Placements != nil && f.judge.Configurations == nil {
+		fail(errors.New("-fleet-seed requires -configuration"))
+	}
+}
+
+// Resolves the player either to a built-in bot, to a local
+// executable, if such file exists, or to a docker image id otherwise.
+func (f *playerFlags) factory(player string) game.PlayerFactory {
+	if name, ok := strings.CutPrefix(player, bots.Prefix); ok {
+		factory, err := bots.NewPlayerFactory(name, 0)
+		if err != nil {
+			fail(err)
+		}
+		return factory
+	}
+
+	if info, err := os.Stat(player); err == nil && !info.IsDir() {
+		path, err := filepath.Abs(player)
+		if err != nil {
+			fail(err)
+		}
+		return process.NewPlayerFactory(path)
+	}
+
+	// Docker is only needed if there are images involved.
+	if f.runner == nil {
+		f.runner = docker.NewSubmissionRunner(newDockerClient(), f.limits())
+	}
+
+	return docker.NewPlayerFactory(f.runner, player)
+}
+
+func printTranscript(transcript *judge.Transcript) {
+	for i, round := range transcript.Rounds {
+		kind := ""
+		if round.Breaker {
+			kind = " (breaker)"
+		}
+		fmt.Printf("round %d%s\n", i, kind)
+
+		for _, e := range round.Exchanges {
+			fmt.Printf("  %-6s > %s\n", e.Role, e.Command)
+			if e.Error != "" {
+				fmt.Printf("  %-6s ! %s\n", e.Role, e.Error)
+			} else {
+				fmt.Printf("  %-6s < %s\n", e.Role, e.Response)
+			}
+		}
+		if round.Truncated {
+			fmt.Println("  ... (truncated)")
+		}
+	}
+}
+
+func printRoundStats(name string, stats judge.RoundStats) {
+	fmt.Printf("%s: %d turns\n", name, stats.Turns)
+	for _, p := range []struct {
+		role  game.Role
+		stats judge.PlayerStats
+	}{{game.RoleMaster, stats.Master}, {game.RoleSlave, stats.Slave}} {
+		fmt.Printf("  %-6s %d shots (%d hits, %d kills, %d misses), used %s\n", p.role,
+			p.stats.Shots, p.stats.Hits, p.stats.Kills, p.stats.Misses, p.stats.Time.Round(time.Millisecond))
+	}
+}
+
+func printSummary(verdict judge.Verdict, duration time.Duration) {
+	fmt.Printf("winner:   %s\n", verdict.Winner)
+	fmt.Printf("reason:   %s\n", verdict.Reason)
+	if verdict.Details != "" {
+		fmt.Printf("details:  %s\n", verdict.Details)
+	}
+	if verdict.Code != "" {
+		fmt.Printf("code:     %s\n", verdict.Code)
+	}
+	fmt.Printf("duration: %s\n", duration.Round(time.Millisecond))
+
+	stats := verdict.Stats
+	if conf := stats.Configuration; conf != nil {
+		fmt.Printf("field:    %s\n", conf)
+	}
+
+	printRoundStats("round", stats.RoundStats)
+	if stats.Breaker != nil {
+		printRoundStats(fmt.Sprintf("breaker (%s, %s)", stats.Breaker.Result, stats.Breaker.Reason), stats.Breaker.RoundStats)
+	}
+}
+
+func runMatch(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("match", flag.ContinueOnError)
+	players := newPlayerFlags(flags)
+	quiet := flags.Bool("q", false, "don't print the transcript")
+	asJson := flags.Bool("json", false, "print verdict and transcript as json")
+	args = parseFlags(flags, args, "<master>", "<slave>")
+	players.check()
+
+	master := players.factory(args[0])
+	slave := players.factory(args[1])
+
+	startedAt := time.Now()
+	verdict, transcript := players.judge.Judge(ctx, master, slave)
+	duration := time.Since(startedAt)
+
+	if ctx.Err() != nil {
+		fail(context.Cause(ctx))
+	}
+
+	if *asJson {
+		err := json.NewEncoder(os.Stdout).Encode(map[string]any{
+			"verdict":    verdict,
+			"transcript": transcript,
+			"duration":   duration.Seconds(),
+		})
+		if err != nil {
+			fail(err)
+		}
+	} else {
+		if !*quiet {
+			printTranscript(transcript)
+			fmt.Println()
+		}
+		printSummary(verdict, duration)
+	}
+
+	os.Exit(int(verdict.Winner))
+}
+
+func runSeries(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("series", flag.ContinueOnError)
+	players := newPlayerFlags(flags)
+	games := flags.Int("games", 2, "maximum number of matches to play")
+	args = parseFlags(flags, args, "<a>", "<b>")
+	players.check()
+
+	series := judge.Series{
+		Judge: players.judge,
+		Games: *games,
+	}
+
+	played := 0
+	series.OnGame = func(g judge.SeriesGame) {
+		played++
+
+		master, slave := args[0], args[1]
+		if !g.FirstIsMaster {
+			master, slave = slave, master
+		}
+
+		fmt.Printf("[%d/%d] %s vs %s: %s (%s) in %s\n", played, *games,
+			master, slave, g.Winner, g.Reason, g.FinishedAt.Sub(g.StartedAt).Round(time.Millisecond))
+	}
+
+	result, err := series.Play(ctx, players.factory(args[0]), players.factory(args[1]))
+	if err != nil {
+		fail(err)
+	}
+
+	fmt.Printf("%s: %g, %s: %g\n", args[0], result.Score[0], args[1], result.Score[1])
+
+	os.Exit(int(result.Winner))
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mrsobakin/itmournament/internal/docker"
+	"github.com/mrsobakin/itmournament/internal/store"
+)
+
+type buildStatus string
+
+const (
+	buildQueued    buildStatus = "queued"
+	buildRunning   buildStatus = "running"
+	buildSucceeded buildStatus = "succeeded"
+	buildFailed    buildStatus = "failed"
+	buildCancelled buildStatus = "cancelled"
+)
+
+var (
+	errBuildCancelled error = errors.New("build cancelled")
+)
+
+func newBuildRecord(id string, src docker.Source, startedAt time.Time, result docker.BuildResult) store.BuildRecord {
+	record := store.BuildRecord{
+		Id:        id,
+		Repo:      src.Repo,
+		Ref:       src.Ref,
+		Src:       src.Src,
+		ImageId:   result.ImageId,
+		Logs:      result.Logs,
+		StartedAt: startedAt,
+		Duration:  time.Since(startedAt),
+	}
+
+	if result.Err != nil {
+		_, record.Error = classifyBuildError(result.Err)
+		record.Details = result.Err.Error()
+	}
+
+	return record
+}
+
+func buildRecordStatus(r *store.BuildRecord) buildStatus {
+	switch {
+	case r.Error == "":
+		return buildSucceeded
+	case r.Error == ErrCancelled:
+		return buildCancelled
+	default:
+		return buildFailed
+	}
+}
+
+func buildRecordView(r *store.BuildRecord) map[string]any {
+	view := map[string]any{
+		"id":         r.Id,
+		"status":     buildRecordStatus(r),
+		"repo":       r.Repo,
+		"ref":        r.Ref,
+		"created_at": r.StartedAt,
+		"duration":   r.Duration.Seconds(),
+		"logs":       r.Logs,
+	}
+
+	if r.Error == "" {
+		view["image_id"] = r.ImageId
+	} else {
+		view["error"] = r.Error
+		view["details"] = r.Details
+	}
+
+	return view
+}
+
+type buildJob struct {
+	id     string
+	source docker.Source
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	mu        sync.Mutex
+	status    buildStatus
+	record    *store.BuildRecord
+	createdAt time.Time
+}
+
+func (j *buildJob) setStatus(status buildStatus) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.status = status
+}
+
+func (j *buildJob) finish(result docker.BuildResult) store.BuildRecord {
+	record := newBuildRecord(j.id, j.source, j.createdAt, result)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.record = &record
+	j.status = buildRecordStatus(&record)
+
+	return record
+}
+
+func (j *buildJob) isFinished() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.record != nil
+}
+
+func (j *buildJob) view() map[string]any {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.record != nil {
+		return buildRecordView(j.record)
+	}
+
+	return map[string]any{
+		"id":         j.id,
+		"status":     j.status,
+		"repo":       j.source.Repo,
+		"ref":        j.source.Ref,
+		"created_at": j.createdAt,
+	}
+}
+
+// Keeps track of build jobs submitted since the server start.
+//
+// Finished jobs are kept here as well, for `finishedJobTTL` after
+// they are saved, or for good if saving them to the store has failed,
+// so that they remain queryable.
+type buildJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*buildJob
+}
+
+func newBuildJobs() *buildJobs {
+	return &buildJobs{
+		jobs: make(map[string]*buildJob),
+	}
+}
+
+func (b *buildJobs) add(src docker.Source) *buildJob {
+	// Build outlives the request that created it.
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	job := &buildJob{
+		id:        newId(),
+		source:    src,
+		ctx:       ctx,
+		cancel:    cancel,
+		status:    buildQueued,
+		createdAt: time.Now(),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.jobs[job.id] = job
+
+	return job
+}
+
+func (b *buildJobs) get(id string) (*buildJob, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	job, ok := b.jobs[id]
+	return job, ok
+}
+
+// Forgets the job once its record is saved, after `finishedJobTTL`.
+func (b *buildJobs) expire(id string) {
+	time.AfterFunc(finishedJobTTL, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.jobs, id)
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrsobakin/itmournament/internal/rating"
+	"github.com/mrsobakin/itmournament/internal/store"
+)
+
+const (
+	EloK       float64 = 32
+	Glicko2Tau float64 = 0.5
+)
+
+// Rates all stored matches.
+//
+// Query parameters:
+//   - system: either `glicko2` (default) or `elo`.
+//   - master_advantage: rating points a master gets for free.
+//     Estimated from the matches themselves by default.
+func (s *server) handleLeaderboard(c *gin.Context) {
+	var params struct {
+		System          string   `form:"system"`
+		MasterAdvantage *float64 `form:"master_advantage"`
+	}
+
+	if !tryBindQuery(c, &params) {
+		return
+	}
+
+	records, err := s.store.Matches(store.MatchFilter{})
+	if err != nil {
+		replyInternalError(c, err)
+		return
+	}
+
+	games := make([]rating.Game, 0, len(records))
+	for _, r := range records {
+		games = append(games, rating.Game{
+			Master: r.MasterImageId,
+			Slave:  r.SlaveImageId,
+			Result: r.Verdict.Winner,
+		})
+	}
+
+	advantage := rating.EstimateMasterAdvantage(games)
+	if params.MasterAdvantage != nil {
+		advantage = *params.MasterAdvantage
+	}
+
+	system := params.System
+	if system == "" {
+		system = "glicko2"
+	}
+
+	var ratings []rating.Rating
+
+	switch system {
+	case "glicko2":
+		g := rating.NewGlicko2(Glicko2Tau, advantage)
+		g.Update(games)
+		ratings = g.Ratings()
+	case "elo":
+		e := rating.NewElo(EloK, advantage)
+		for _, game := range games {
+			e.Update(game)
+		}
+		ratings = e.Ratings()
+	default:
+		c.JSON(422, map[string]any{
+			"error":   ErrBadFormat,
+			"details": "unknown rating system: " + system,
+		})
+		return
+	}
+
+	c.JSON(200, map[string]any{
+		"system":           system,
+		"master_advantage": advantage,
+		"ratings":          ratings,
+	})
+}
@@ -2,15 +2,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"golang.org/x/sync/semaphore"
 
 	"github.com/docker/docker/client"
 	"github.com/gin-gonic/gin"
+	"github.com/mrsobakin/itmournament/internal/bots"
 	"github.com/mrsobakin/itmournament/internal/docker"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/store"
 )
 
 func InitDockerThings(limits docker.Limits) (*docker.SubmissionBuilder, *docker.SubmissionRunner, error) {
@@ -46,12 +53,93 @@ func NewServer() *server {
 		panic(err)
 	}
 
+	storeDir, ok := os.LookupEnv("STORE_DIR")
+	if !ok {
+		storeDir = "store"
+	}
+
+	st, err := store.OpenFileStore(storeDir)
+	if err != nil {
+		panic(err)
+	}
+
+	rules, ok := os.LookupEnv("RULES")
+	if !ok {
+		rules = judge.DefaultRules
+	}
+
+	defaultRules, err := judge.LookupRules(rules)
+	if err != nil {
+		panic(err)
+	}
+
+	breakerShooter, err := judge.LookupBreakerShooter(os.Getenv("BREAKER_SHOOTER"))
+	if err != nil {
+		panic(err)
+	}
+
+	// Space separated, e.g. `10x10:4,3,2,1 12x12:5,4,3,2`.
+	var configurations judge.ConfigurationSource
+	var parsedConfs []field.Configuration
+	if confs := strings.Fields(os.Getenv("CONFIGURATIONS")); len(confs) > 0 {
+		parsedConfs = make([]field.Configuration, len(confs))
+		for i, conf := range confs {
+			if parsedConfs[i], err = field.ParseConfiguration(conf); err != nil {
+				panic(err)
+			}
+		}
+		configurations = judge.SampledConfiguration(parsedConfs...)
+	}
+
+	// Judge places ships itself if the seed is set and not 0.
+	var placements judge.PlacementSource
+	if seed, ok := os.LookupEnv("FLEET_SEED"); ok {
+		parsed, err := strconv.ParseUint(seed, 10, 64)
+		if err != nil {
+			panic(err)
+		}
+		if parsed != 0 {
+			placements = bots.Placements(parsed)
+		}
+	}
+	if placements != nil && configurations == nil {
+		panic(errors.New("FLEET_SEED requires CONFIGURATIONS"))
+	}
+
+	maxShipSize := field.DefaultMaxShipSize
+	if size, ok := os.LookupEnv("MAX_SHIP_SIZE"); ok {
+		if maxShipSize, err = strconv.Atoi(size); err != nil {
+			panic(err)
+		}
+		if maxShipSize <= 0 || maxShipSize > field.MaxShipSize {
+			panic(fmt.Errorf("MAX_SHIP_SIZE must be within 1..%d, got %d", field.MaxShipSize, maxShipSize))
+		}
+	}
+
+	// Checked against the default rules only, as others may be
+	// requested per match.
+	checker := &judge.Judge{Rules: defaultRules, MaxShipSize: maxShipSize}
+	for _, conf := range parsedConfs {
+		if err := checker.CheckConfiguration(conf); err != nil {
+			panic(err)
+		}
+	}
+
 	nCPU := runtime.NumCPU() * 2
 
 	return &server{
 		builder: builder,
 		runner:  runner,
 		jobs:    semaphore.NewWeighted(int64(nCPU)),
+		builds:  newBuildJobs(),
+		matches: newMatchJobs(),
+		store:   st,
+		rules:   rules,
+
+		breakerShooter: breakerShooter,
+		configurations: configurations,
+		placements:     placements,
+		maxShipSize:    maxShipSize,
 	}
 }
 
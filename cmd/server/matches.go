@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/store"
+)
+
+type matchJob struct {
+	id            string
+	masterImageId string
+	slaveImageId  string
+	rulesName     string
+	rules         judge.Rules
+	master, slave game.PlayerFactory
+
+	mu     sync.Mutex
+	events []judge.Event
+	done   bool
+
+	// Closed and replaced on every new event.
+	updated chan struct{}
+}
+
+func (j *matchJob) publish(e judge.Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.events = append(j.events, e)
+	if e.Phase == judge.PhaseVerdict {
+		j.done = true
+	}
+
+	close(j.updated)
+	j.updated = make(chan struct{})
+}
+
+// Returns events starting from the given index, whether there will be
+// no more events, and a channel that is closed once there are new ones.
+func (j *matchJob) eventsSince(i int) ([]judge.Event, bool, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.events[i:], j.done, j.updated
+}
+
+// Finished jobs are forgotten this long after they are saved to the
+// store. Until then, clients that subscribe late still get all events.
+const finishedJobTTL = time.Minute
+
+// Keeps track of matches submitted since the server start, so that
+// their progress can be streamed.
+type matchJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*matchJob
+}
+
+func newMatchJobs() *matchJobs {
+	return &matchJobs{
+		jobs: make(map[string]*matchJob),
+	}
+}
+
+func (m *matchJobs) add(job *matchJob) *matchJob {
+	job.id = newId()
+	job.updated = make(chan struct{})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.jobs[job.id] = job
+
+	return job
+}
+
+func (m *matchJobs) get(id string) (*matchJob, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+// Forgets the job once its record is saved, after `finishedJobTTL`.
+func (m *matchJobs) expire(id string) {
+	time.AfterFunc(finishedJobTTL, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		delete(m.jobs, id)
+	})
+}
+
+func (s *server) runMatchJob(job *matchJob) {
+	// Match outlives the request that created it.
+	ctx := context.Background()
+
+	s.jobs.Acquire(ctx, 2)
+	defer s.jobs.Release(2)
+
+	startedAt := time.Now()
+
+	verdict, transcript := s.judgeMatchJob(ctx, job)
+
+	// Nobody is to blame, so the match isn't counted. Its events still
+	// tell subscribers what went wrong.
+	if verdict.Reason == judge.JudgeError {
+		log.Printf("match %s has failed: %s", job.id, verdict.Details)
+		s.matches.expire(job.id)
+		return
+	}
+
+	saved := s.saveMatch(store.MatchRecord{
+		Id:            job.id,
+		MasterImageId: job.masterImageId,
+		SlaveImageId:  job.slaveImageId,
+		Verdict:       verdict,
+		Rules:         job.rulesName,
+		StartedAt:     startedAt,
+		FinishedAt:    time.Now(),
+	}, transcript)
+
+	// Otherwise, the job is the only place the match is known from.
+	if saved {
+		s.matches.expire(job.id)
+	}
+}
+
+// Judges the match, turning a panic into a judge error, as there is
+// no request handler left to recover from it.
+func (s *server) judgeMatchJob(ctx context.Context, job *matchJob) (verdict judge.Verdict, transcript *judge.Transcript) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		log.Printf("match %s has panicked: %v", job.id, r)
+
+		verdict = judge.Verdict{
+			Winner:  judge.Tie,
+			Reason:  judge.JudgeError,
+			Details: fmt.Sprintf("judge has panicked: %v", r),
+		}
+		transcript = &judge.Transcript{}
+
+		job.publish(judge.Event{Phase: judge.PhaseVerdict, Time: time.Now(), Verdict: &verdict})
+	}()
+
+	j := s.newJudge(job.rules)
+	j.Observer = job.publish
+
+	return j.Judge(ctx, job.master, job.slave)
+}
+
+// Same as `/run_match`, but doesn't wait for the verdict. Progress
+// can be followed at `/matches/:id/events`.
+func (s *server) handleCreateMatch(c *gin.Context) {
+	var params struct {
+		MasterImageId string `json:"master_image_id" binding:"required"`
+		SlaveImageId  string `json:"slave_image_id" binding:"required"`
+		Rules         string `json:"rules"`
+	}
+
+	if !tryBindParams(c, &params) {
+		return
+	}
+
+	rulesName, rules, ok := s.tryLookupRules(c, params.Rules)
+	if !ok {
+		return
+	}
+
+	master, ok := s.tryPlayerFactory(c, params.MasterImageId)
+	if !ok {
+		return
+	}
+
+	slave, ok := s.tryPlayerFactory(c, params.SlaveImageId)
+	if !ok {
+		return
+	}
+
+	job := s.matches.add(&matchJob{
+		masterImageId: params.MasterImageId,
+		slaveImageId:  params.SlaveImageId,
+		rulesName:     rulesName,
+		rules:         rules,
+		master:        master,
+		slave:         slave,
+	})
+
+	go s.runMatchJob(job)
+
+	c.JSON(202, map[string]any{
+		"id":              job.id,
+		"master_image_id": job.masterImageId,
+		"slave_image_id":  job.slaveImageId,
+		"rules":           job.rulesName,
+	})
+}
+
+// Streams match progress as server-sent events, named after
+// their phases. Events that already happened are sent first.
+//
+// Matches played before the restart only have the verdict.
+func (s *server) handleMatchEvents(c *gin.Context) {
+	job, ok := s.matches.get(c.Param("id"))
+	if !ok {
+		record, err := s.store.Match(c.Param("id"))
+		if errors.Is(err, store.ErrNotFound) {
+			replyNotFound(c, "no such match")
+			return
+		}
+		if err != nil {
+			replyInternalError(c, err)
+			return
+		}
+
+		c.SSEvent(string(judge.PhaseVerdict), judge.Event{
+			Phase:   judge.PhaseVerdict,
+			Time:    record.FinishedAt,
+			Verdict: &record.Verdict,
+		})
+		return
+	}
+
+	sent := 0
+	c.Stream(func(io.Writer) bool {
+		events, done, updated := job.eventsSince(sent)
+
+		for _, e := range events {
+			c.SSEvent(string(e.Phase), e)
+		}
+		sent += len(events)
+
+		if done {
+			return false
+		}
+
+		if len(events) == 0 {
+			select {
+			case <-updated:
+			case <-c.Request.Context().Done():
+				return false
+			}
+		}
+
+		return true
+	})
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/replay"
+	"github.com/mrsobakin/itmournament/internal/store"
+)
+
+// Failing to save a record shouldn't fail the request, as the result
+// itself is still valid and is returned to the client.
+// Reports whether the record has been saved.
+func (s *server) saveBuild(record store.BuildRecord) bool {
+	if err := s.store.AddBuild(record); err != nil {
+		log.Printf("failed to save build %s: %v", record.Id, err)
+		return false
+	}
+	return true
+}
+
+// Reports whether the record has been saved. Transcript is of less
+// importance, and failing to save it is only logged.
+func (s *server) saveMatch(record store.MatchRecord, transcript *judge.Transcript) bool {
+	if err := s.store.AddMatch(record); err != nil {
+		log.Printf("failed to save match %s: %v", record.Id, err)
+		return false
+	}
+
+	if err := s.store.AddTranscript(record.Id, transcript); err != nil {
+		log.Printf("failed to save transcript of match %s: %v", record.Id, err)
+	}
+
+	return true
+}
+
+func (s *server) handleListBuilds(c *gin.Context) {
+	records, err := s.store.Builds(store.BuildFilter{
+		Repo:    c.Query("repo"),
+		ImageId: c.Query("image_id"),
+	})
+	if err != nil {
+		replyInternalError(c, err)
+		return
+	}
+
+	views := make([]map[string]any, 0, len(records))
+	for i := range records {
+		views = append(views, buildRecordView(&records[i]))
+	}
+
+	c.JSON(200, views)
+}
+
+func (s *server) handleListMatches(c *gin.Context) {
+	records, err := s.store.Matches(store.MatchFilter{
+		MasterImageId: c.Query("master_image_id"),
+		SlaveImageId:  c.Query("slave_image_id"),
+		ImageId:       c.Query("image_id"),
+	})
+	if err != nil {
+		replyInternalError(c, err)
+		return
+	}
+
+	if records == nil {
+		records = []store.MatchRecord{}
+	}
+
+	c.JSON(200, records)
+}
+
+func (s *server) handleGetMatch(c *gin.Context) {
+	record, err := s.store.Match(c.Param("id"))
+	if errors.Is(err, store.ErrNotFound) {
+		// Match may still be running.
+		if job, ok := s.matches.get(c.Param("id")); ok {
+			c.JSON(200, map[string]any{
+				"id":              job.id,
+				"master_image_id": job.masterImageId,
+				"slave_image_id":  job.slaveImageId,
+				"rules":           job.rulesName,
+				"running":         true,
+			})
+			return
+		}
+
+		replyNotFound(c, "no such match")
+		return
+	}
+	if err != nil {
+		replyInternalError(c, err)
+		return
+	}
+
+	c.JSON(200, record)
+}
+
+func (s *server) handleGetTranscript(c *gin.Context) {
+	transcript, err := s.store.Transcript(c.Param("id"))
+	if errors.Is(err, store.ErrNotFound) {
+		replyNotFound(c, "no such transcript")
+		return
+	}
+	if err != nil {
+		replyInternalError(c, err)
+		return
+	}
+
+	c.JSON(200, transcript)
+}
+
+// Renders state of a match round after the given turn.
+//
+// Query parameters:
+//   - round: index of the round, 0 by default.
+//   - turn: number of shots to replay, all of them by default.
+//   - format: either `svg` (default) or `text`.
+func (s *server) handleGetReplay(c *gin.Context) {
+	var params struct {
+		Round  int    `form:"round"`
+		Turn   *int   `form:"turn"`
+		Format string `form:"format"`
+	}
+
+	if !tryBindQuery(c, &params) {
+		return
+	}
+
+	transcript, err := s.store.Transcript(c.Param("id"))
+	if errors.Is(err, store.ErrNotFound) {
+		replyNotFound(c, "no such transcript")
+		return
+	}
+	if err != nil {
+		replyInternalError(c, err)
+		return
+	}
+
+	if params.Round < 0 || params.Round >= len(transcript.Rounds) {
+		replyNotFound(c, "no such round")
+		return
+	}
+
+	r, err := replay.New(transcript.Rounds[params.Round])
+	if err != nil {
+		replyNotFound(c, err.Error())
+		return
+	}
+
+	turn := r.Turns()
+	if params.Turn != nil {
+		turn = *params.Turn
+	}
+	state := r.State(turn)
+
+	var buf bytes.Buffer
+	var contentType string
+
+	switch params.Format {
+	case "", "svg":
+		contentType = "image/svg+xml"
+		err = state.WriteSVG(&buf)
+	case "text":
+		contentType = "text/plain; charset=utf-8"
+		buf.WriteString("master:\n")
+		err = state.WriteASCII(&buf, game.RoleMaster)
+		buf.WriteString("\nslave:\n")
+		err = errors.Join(err, state.WriteASCII(&buf, game.RoleSlave))
+	default:
+		c.JSON(422, map[string]any{
+			"error":   ErrBadFormat,
+			"details": "unknown format: " + params.Format,
+		})
+		return
+	}
+
+	if err != nil {
+		replyInternalError(c, err)
+		return
+	}
+
+	c.Data(200, contentType, buf.Bytes())
+}
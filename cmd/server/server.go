@@ -9,14 +9,23 @@ import (
 	"github.com/gin-gonic/gin"
 	"golang.org/x/sync/semaphore"
 
+	"github.com/mrsobakin/itmournament/internal/bots"
+	"github.com/mrsobakin/itmournament/internal/conformance"
 	"github.com/mrsobakin/itmournament/internal/docker"
+	"github.com/mrsobakin/itmournament/internal/game"
 	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/store"
 )
 
 const (
 	BuildTimeout  time.Duration = 100 * time.Minute
 	PlayerTimeout time.Duration = 2 * time.Minute
 	GlobalTimeout time.Duration = 7 * time.Minute
+
+	// Shots the judge fires in the breaker round, so that it takes
+	// about the same time regardless of the field size. Master that
+	// hasn't won by then gets a tie, same as on reaching the shot limit.
+	BreakerShots int64 = 10_000
 )
 
 const (
@@ -24,6 +33,10 @@ const (
 	ErrBadFormat string = "bad_format"
 	ErrUnknown   string = "unknown"
 	ErrTimeout   string = "timeout"
+	ErrNotFound  string = "not_found"
+	ErrFinished  string = "finished"
+	ErrCancelled string = "cancelled"
+	ErrInternal  string = "internal"
 )
 
 var (
@@ -34,6 +47,100 @@ type server struct {
 	builder *docker.SubmissionBuilder
 	runner  *docker.SubmissionRunner
 	jobs    *semaphore.Weighted
+	builds  *buildJobs
+	matches *matchJobs
+	store   store.Store
+
+	// Name of the rule set matches are judged by, unless requested otherwise.
+	rules string
+
+	breakerShooter judge.BreakerShooter
+
+	// If set, configurations are chosen by the judge rather than by masters.
+	configurations judge.ConfigurationSource
+
+	// If set, fleets are placed by the judge rather than by players.
+	placements judge.PlacementSource
+
+	// Largest ship size players are asked and told about.
+	maxShipSize int
+}
+
+func (s *server) newJudge(rules judge.Rules) *judge.Judge {
+	return &judge.Judge{
+		PlayerTimeout:  PlayerTimeout,
+		GlobalTimeout:  GlobalTimeout,
+		Rules:          rules,
+		BreakerShooter: s.breakerShooter,
+		BreakerShots:   BreakerShots,
+		Configurations: s.configurations,
+		Placements:     s.placements,
+		MaxShipSize:    s.maxShipSize,
+	}
+}
+
+// Resolves player id either to a bot, if it's prefixed with `bot:`,
+// or to a docker image otherwise, replying with an error if there is
+// no such bot.
+func (s *server) tryPlayerFactory(c *gin.Context, id string) (game.PlayerFactory, bool) {
+	name, ok := strings.CutPrefix(id, bots.Prefix)
+	if !ok {
+		return docker.NewPlayerFactory(s.runner, id), true
+	}
+
+	factory, err := bots.NewPlayerFactory(name, 0)
+	if err != nil {
+		c.JSON(422, map[string]any{
+			"error":   ErrBadFormat,
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	return factory, true
+}
+
+// Resolves rules requested for a match, replying with an error if
+// they are unknown.
+func (s *server) tryLookupRules(c *gin.Context, name string) (string, judge.Rules, bool) {
+	if name == "" {
+		name = s.rules
+	}
+
+	rules, err := judge.LookupRules(name)
+	if err != nil {
+		c.JSON(422, map[string]any{
+			"error":   ErrBadFormat,
+			"details": err.Error(),
+		})
+		return "", nil, false
+	}
+
+	return name, rules, true
+}
+
+// Maps build error onto the HTTP status code and the error kind.
+func classifyBuildError(err error) (int, string) {
+	if strings.HasPrefix(err.Error(), "failed to solve: failed to load cache key: error fetching default branch for repository https://github.com/.git:") {
+		return 400, ErrBadRepo
+	}
+
+	if errors.Is(err, errBuildTimeout) {
+		return 408, ErrTimeout
+	}
+
+	if errors.Is(err, errBuildCancelled) {
+		return 409, ErrCancelled
+	}
+
+	return 400, ErrUnknown
+}
+
+func (s *server) build(ctx context.Context, src docker.Source) docker.BuildResult {
+	timeoutCtx, cancel := context.WithTimeoutCause(ctx, BuildTimeout, errBuildTimeout)
+	defer cancel()
+
+	return s.builder.Build(timeoutCtx, src)
 }
 
 func (s *server) handleBuild(c *gin.Context) {
@@ -49,71 +156,309 @@ func (s *server) handleBuild(c *gin.Context) {
 	s.jobs.Acquire(c, 1)
 	defer s.jobs.Release(1)
 
-	timeoutCtx, cancel := context.WithTimeoutCause(context.Background(), BuildTimeout, errBuildTimeout)
-	defer cancel()
-
-	result := s.builder.Build(timeoutCtx, docker.Source{
+	src := docker.Source{
 		Repo: params.Repo,
 		Ref:  params.Ref,
-	})
+	}
+
+	startedAt := time.Now()
+	result := s.build(context.Background(), src)
+	record := newBuildRecord(newId(), src, startedAt, result)
+	s.saveBuild(record)
 
 	if result.Err == nil {
 		c.JSON(200, map[string]any{
+			"id":       record.Id,
 			"image_id": result.ImageId,
 			"logs":     result.Logs,
 		})
 		return
 	}
 
-	var err string
-	var errCode int
-
-	if strings.HasPrefix(result.Err.Error(), "failed to solve: failed to load cache key: error fetching default branch for repository https://github.com/.git:") {
-		errCode = 400
-		err = ErrBadRepo
-	} else if errors.Is(result.Err, errBuildTimeout) {
-		errCode = 408
-		err = ErrTimeout
-	} else {
-		errCode = 400
-		err = ErrUnknown
-	}
+	errCode, err := classifyBuildError(result.Err)
 
 	c.JSON(errCode, map[string]any{
+		"id":      record.Id,
 		"error":   err,
 		"details": result.Err.Error(),
 		"logs":    result.Logs,
 	})
 }
 
+func (s *server) runBuildJob(job *buildJob) {
+	defer job.cancel(nil)
+
+	if err := s.jobs.Acquire(job.ctx, 1); err != nil {
+		if s.saveBuild(job.finish(docker.BuildResult{Err: context.Cause(job.ctx)})) {
+			s.builds.expire(job.id)
+		}
+		return
+	}
+	defer s.jobs.Release(1)
+
+	job.setStatus(buildRunning)
+	result := s.build(job.ctx, job.source)
+
+	// Builder doesn't necessarily preserve the cancellation cause.
+	if result.Err != nil && errors.Is(context.Cause(job.ctx), errBuildCancelled) {
+		result.Err = errBuildCancelled
+	}
+
+	if s.saveBuild(job.finish(result)) {
+		s.builds.expire(job.id)
+	}
+}
+
+func (s *server) handleCreateBuild(c *gin.Context) {
+	var params struct {
+		Repo string `json:"repo" binding:"required"`
+		Ref  string `json:"ref" binding:"required"`
+	}
+
+	if !tryBindParams(c, &params) {
+		return
+	}
+
+	job := s.builds.add(docker.Source{
+		Repo: params.Repo,
+		Ref:  params.Ref,
+	})
+
+	go s.runBuildJob(job)
+
+	c.JSON(202, job.view())
+}
+
+func (s *server) handleGetBuild(c *gin.Context) {
+	if job, ok := s.builds.get(c.Param("id")); ok {
+		c.JSON(200, job.view())
+		return
+	}
+
+	// Builds made before the restart are only in the store.
+	record, err := s.store.Build(c.Param("id"))
+	if errors.Is(err, store.ErrNotFound) {
+		replyNotFound(c, "no such build")
+		return
+	}
+	if err != nil {
+		replyInternalError(c, err)
+		return
+	}
+
+	c.JSON(200, buildRecordView(&record))
+}
+
+func (s *server) handleCancelBuild(c *gin.Context) {
+	job, ok := s.builds.get(c.Param("id"))
+	if !ok {
+		if _, err := s.store.Build(c.Param("id")); err == nil {
+			replyFinished(c)
+		} else {
+			replyNotFound(c, "no such build")
+		}
+		return
+	}
+
+	if job.isFinished() {
+		replyFinished(c)
+		return
+	}
+
+	job.cancel(errBuildCancelled)
+
+	c.JSON(202, job.view())
+}
+
 func (s *server) handleMatch(c *gin.Context) {
 	var params struct {
 		MasterImageId string `json:"master_image_id" binding:"required"`
 		SlaveImageId  string `json:"slave_image_id" binding:"required"`
+		Rules         string `json:"rules"`
 	}
 
 	if !tryBindParams(c, &params) {
 		return
 	}
 
+	rulesName, rules, ok := s.tryLookupRules(c, params.Rules)
+	if !ok {
+		return
+	}
+
+	master, ok := s.tryPlayerFactory(c, params.MasterImageId)
+	if !ok {
+		return
+	}
+
+	slave, ok := s.tryPlayerFactory(c, params.SlaveImageId)
+	if !ok {
+		return
+	}
+
 	s.jobs.Acquire(c, 2)
 	defer s.jobs.Release(2)
 
-	j := judge.Judge{
-		PlayerTimeout: PlayerTimeout,
-		GlobalTimeout: GlobalTimeout,
+	j := s.newJudge(rules)
+
+	startedAt := time.Now()
+
+	verdict, transcript := j.Judge(c.Request.Context(), master, slave)
+
+	// Nobody is waiting for the verdict, and it is meaningless anyway.
+	if c.Request.Context().Err() != nil {
+		return
+	}
+
+	// Nobody is to blame, so the match isn't counted.
+	if verdict.Reason == judge.JudgeError {
+		replyInternalError(c, errors.New(verdict.Details))
+		return
+	}
+
+	record := store.MatchRecord{
+		Id:            newId(),
+		MasterImageId: params.MasterImageId,
+		SlaveImageId:  params.SlaveImageId,
+		Verdict:       verdict,
+		Rules:         rulesName,
+		StartedAt:     startedAt,
+		FinishedAt:    time.Now(),
+	}
+	s.saveMatch(record, transcript)
+
+	c.JSON(200, struct {
+		Id string `json:"id"`
+		judge.Verdict
+	}{record.Id, verdict})
+}
+
+// Runs the image through the protocol conformance suite, replying
+// with a report of which checks have passed.
+func (s *server) handleCheck(c *gin.Context) {
+	var params struct {
+		ImageId string `json:"image_id" binding:"required"`
+	}
+
+	if !tryBindParams(c, &params) {
+		return
 	}
 
-	verdict := j.Judge(
-		c.Request.Context(),
-		NewDockerFactory(s.runner, params.MasterImageId),
-		NewDockerFactory(s.runner, params.SlaveImageId),
-	)
+	player, ok := s.tryPlayerFactory(c, params.ImageId)
+	if !ok {
+		return
+	}
+
+	s.jobs.Acquire(c, 1)
+	defer s.jobs.Release(1)
 
-	c.JSON(200, verdict)
+	report := conformance.Run(c.Request.Context(), player, PlayerTimeout)
+
+	if c.Request.Context().Err() != nil {
+		return
+	}
+
+	c.JSON(200, report)
+}
+
+// Plays a best-of-N series, swapping roles every game.
+//
+// Each game is saved as a separate match.
+func (s *server) handleSeries(c *gin.Context) {
+	var params struct {
+		FirstImageId  string `json:"first_image_id" binding:"required"`
+		SecondImageId string `json:"second_image_id" binding:"required"`
+		Games         int    `json:"games" binding:"required,min=1,max=15"`
+		Rules         string `json:"rules"`
+	}
+
+	if !tryBindParams(c, &params) {
+		return
+	}
+
+	rulesName, rules, ok := s.tryLookupRules(c, params.Rules)
+	if !ok {
+		return
+	}
+
+	first, ok := s.tryPlayerFactory(c, params.FirstImageId)
+	if !ok {
+		return
+	}
+
+	second, ok := s.tryPlayerFactory(c, params.SecondImageId)
+	if !ok {
+		return
+	}
+
+	s.jobs.Acquire(c, 2)
+	defer s.jobs.Release(2)
+
+	type gameView struct {
+		Id            string `json:"id"`
+		MasterImageId string `json:"master_image_id"`
+		SlaveImageId  string `json:"slave_image_id"`
+		judge.Verdict
+	}
+	var games []gameView
+
+	series := judge.Series{
+		Judge: s.newJudge(rules),
+		Games: params.Games,
+		OnGame: func(g judge.SeriesGame) {
+			record := store.MatchRecord{
+				Id:            newId(),
+				MasterImageId: params.FirstImageId,
+				SlaveImageId:  params.SecondImageId,
+				Verdict:       g.Verdict,
+				Rules:         rulesName,
+				StartedAt:     g.StartedAt,
+				FinishedAt:    g.FinishedAt,
+			}
+			if !g.FirstIsMaster {
+				record.MasterImageId, record.SlaveImageId = record.SlaveImageId, record.MasterImageId
+			}
+
+			s.saveMatch(record, g.Transcript)
+
+			games = append(games, gameView{record.Id, record.MasterImageId, record.SlaveImageId, g.Verdict})
+		},
+	}
+
+	result, err := series.Play(c.Request.Context(), first, second)
+
+	// Nobody is waiting for the result.
+	if c.Request.Context().Err() != nil {
+		return
+	}
+
+	// Judge has failed, and would fail the rest of the games the same way.
+	if err != nil {
+		replyInternalError(c, err)
+		return
+	}
+
+	c.JSON(200, map[string]any{
+		"winner": result.Winner,
+		"score":  result.Score,
+		"games":  games,
+	})
 }
 
 func (s *server) RegisterEndpoints(e *gin.Engine) {
 	e.POST("/build", s.handleBuild)
+	e.POST("/builds", s.handleCreateBuild)
+	e.GET("/builds/:id", s.handleGetBuild)
+	e.DELETE("/builds/:id", s.handleCancelBuild)
+	e.GET("/builds", s.handleListBuilds)
 	e.POST("/run_match", s.handleMatch)
+	e.POST("/run_series", s.handleSeries)
+	e.POST("/check", s.handleCheck)
+	e.POST("/matches", s.handleCreateMatch)
+	e.GET("/matches", s.handleListMatches)
+	e.GET("/matches/:id", s.handleGetMatch)
+	e.GET("/matches/:id/events", s.handleMatchEvents)
+	e.GET("/matches/:id/transcript", s.handleGetTranscript)
+	e.GET("/matches/:id/replay", s.handleGetReplay)
+	e.GET("/leaderboard", s.handleLeaderboard)
 }
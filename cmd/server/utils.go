@@ -1,12 +1,10 @@
 package main
 
 import (
-	"context"
+	"crypto/rand"
+	"encoding/hex"
 
 	"github.com/gin-gonic/gin"
-
-	"github.com/mrsobakin/itmournament/internal/docker"
-	"github.com/mrsobakin/itmournament/internal/game"
 )
 
 func tryBindParams(ctx *gin.Context, obj any) (ok bool) {
@@ -20,23 +18,40 @@ func tryBindParams(ctx *gin.Context, obj any) (ok bool) {
 	return true
 }
 
-type dockerFactory struct {
-	runner  *docker.SubmissionRunner
-	imageId string
+func tryBindQuery(ctx *gin.Context, obj any) (ok bool) {
+	if err := ctx.ShouldBindQuery(obj); err != nil {
+		ctx.JSON(422, map[string]any{
+			"error":   ErrBadFormat,
+			"details": err.Error(),
+		})
+		return false
+	}
+	return true
 }
 
-func NewDockerFactory(runner *docker.SubmissionRunner, imageId string) *dockerFactory {
-	return &dockerFactory{
-		runner,
-		imageId,
-	}
+func newId() string {
+	var id [16]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
 }
 
-func (d *dockerFactory) NewPlayer(ctx context.Context) game.Player {
-	p, err := docker.NewDockerPlayer(d.runner, ctx, d.imageId)
-	if err != nil {
-		panic(err)
-	}
+func replyNotFound(ctx *gin.Context, details string) {
+	ctx.JSON(404, map[string]any{
+		"error":   ErrNotFound,
+		"details": details,
+	})
+}
+
+func replyInternalError(ctx *gin.Context, err error) {
+	ctx.JSON(500, map[string]any{
+		"error":   ErrInternal,
+		"details": err.Error(),
+	})
+}
 
-	return p
+func replyFinished(ctx *gin.Context) {
+	ctx.JSON(409, map[string]any{
+		"error":   ErrFinished,
+		"details": "build is already finished",
+	})
 }
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/docker/client"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/mrsobakin/itmournament/internal/bots"
+	"github.com/mrsobakin/itmournament/internal/docker"
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/tournament"
+)
+
+// Reads image ids of successfully built submissions from build logs
+// produced by `scripts/build.py`.
+func loadImageIds(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var builds []struct {
+		ImageId string `json:"image_id"`
+	}
+
+	if err := json.Unmarshal(data, &builds); err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, build := range builds {
+		if build.ImageId != "" {
+			ids = append(ids, build.ImageId)
+		}
+	}
+
+	return ids, nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
+
+// Participants aren't shuffled here, as their order is the seeding,
+// and it must stay the same for the tournament to be resumable.
+func runSwiss(ctx context.Context, t *tournament.Tournament, ids []string, rounds int) error {
+	var done atomic.Int64
+
+	t.OnResult = func(r tournament.MatchResult) {
+		fmt.Printf("[%d] %s vs %s: %s (%s)\n", done.Add(1), r.Master, r.Slave, r.Winner, r.Reason)
+	}
+
+	t.OnRound = func(round tournament.Round) {
+		if round.GaveUp {
+			fmt.Fprintln(os.Stderr, "warning: pairing search has given up, some pairs of this round may repeat needlessly")
+		}
+	}
+
+	standings, err := t.RunSwiss(ctx, ids, rounds)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%4s  %-64s  %5s  %8s  %8s  %4s\n", "#", "image id", "score", "buchholz", "s-b", "wins")
+	for i, st := range standings {
+		fmt.Printf("%4d  %-64s  %5.1f  %8.1f  %8.2f  %4d\n", i+1, st.Id, st.Score, st.Buchholz, st.SonnebornBerger, st.Wins)
+	}
+
+	return nil
+}
+
+// Pairs are shuffled, so that a run that is interrupted early has
+// played a bit of everyone rather than all matches of the first ones.
+func runRoundRobin(ctx context.Context, t *tournament.Tournament, ids []string) error {
+	var done atomic.Int64
+
+	pending := t.Pending(tournament.RoundRobin(ids))
+	rand.Shuffle(len(pending), func(i, j int) {
+		pending[i], pending[j] = pending[j], pending[i]
+	})
+
+	t.OnResult = func(r tournament.MatchResult) {
+		fmt.Printf("[%d/%d] %s vs %s: %s (%s)\n", done.Add(1), len(pending), r.Master, r.Slave, r.Winner, r.Reason)
+	}
+
+	return t.Run(ctx, pending)
+}
+
+func main() {
+	buildsPath := flag.String("builds", "build_logs.json", "build logs with image ids of participants")
+	resultsPath := flag.String("results", "match_results.json", "file to append match results to")
+	jobs := flag.Int("jobs", runtime.NumCPU()*2, "number of concurrently running players")
+	memory := flag.Int64("memory", 70, "player memory limit in MiB")
+	vcpus := flag.Float64("vcpus", 1, "player cpu limit")
+	playerTimeout := flag.Duration("player-timeout", 2*time.Minute, "time limit of a single player")
+	globalTimeout := flag.Duration("global-timeout", 7*time.Minute, "time limit of a whole match")
+	swissRounds := flag.Int("swiss", 0, "number of swiss rounds to play instead of a full round robin")
+	rulesName := flag.String("rules", judge.DefaultRules, "rule set to judge by")
+	var confs []field.Configuration
+	flag.Func("configuration", "configuration chosen by the judge instead of the master, e.g. 10x10:4,3,2,1.\nIf repeated, one of them is sampled for every match", func(s string) error {
+		conf, err := field.ParseConfiguration(s)
+		confs = append(confs, conf)
+		return err
+	})
+	maxShipSize := flag.Int("max-ship-size", field.DefaultMaxShipSize, "largest ship size players are asked and told about")
+	fleetSeed := flag.Uint64("fleet-seed", 0, "if not 0, the judge places ships of both players itself, generating\nthe same fleets for the same seed. Requires -configuration")
+	flag.Parse()
+
+	// Each match holds 2 jobs, so fewer would never let one start.
+	if *jobs < 2 {
+		fail(fmt.Errorf("-jobs must be at least 2, got %d", *jobs))
+	}
+
+	rules, err := judge.LookupRules(*rulesName)
+	if err != nil {
+		fail(err)
+	}
+
+	j := &judge.Judge{
+		PlayerTimeout: *playerTimeout,
+		GlobalTimeout: *globalTimeout,
+		Rules:         rules,
+		MaxShipSize:   *maxShipSize,
+	}
+	for _, conf := range confs {
+		if err := j.CheckConfiguration(conf); err != nil {
+			fail(err)
+		}
+	}
+	if len(confs) > 0 {
+		j.Configurations = judge.SampledConfiguration(confs...)
+	}
+	if *fleetSeed != 0 {
+		if len(confs) == 0 {
+			fail(errors.New("-fleet-seed requires -configuration"))
+		}
+		j.Placements = bots.Placements(*fleetSeed)
+	}
+
+	ids, err := loadImageIds(*buildsPath)
+	if err != nil {
+		fail(err)
+	}
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		fail(err)
+	}
+
+	results, err := tournament.OpenResultLog(*resultsPath)
+	if err != nil {
+		fail(err)
+	}
+
+	runner := docker.NewSubmissionRunner(cli, docker.Limits{
+		Memory: *memory * 1024 * 1024,
+		VCPUs:  *vcpus,
+	})
+
+	t := tournament.Tournament{
+		Judge: j,
+		Players: func(id string) game.PlayerFactory {
+			return docker.NewPlayerFactory(runner, id)
+		},
+		Jobs:    semaphore.NewWeighted(int64(*jobs)),
+		Results: results,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *swissRounds > 0 {
+		err = runSwiss(ctx, &t, ids, *swissRounds)
+	} else {
+		err = runRoundRobin(ctx, &t, ids)
+	}
+
+	// Closed explicitly, as `fail` exits without running deferred calls.
+	if closeErr := results.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fail(err)
+	}
+}
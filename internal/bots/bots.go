@@ -0,0 +1,244 @@
+package bots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+// Prefix bots are referenced by in place of image ids, e.g. `bot:parity`.
+const Prefix string = "bot:"
+
+// Configuration bots choose when playing as master.
+var DefaultConfiguration = field.Configuration{
+	W:     10,
+	H:     10,
+	Sizes: []int64{4, 3, 2, 1},
+}
+
+// Bot playing in-process, speaking the same protocol as submissions do.
+//
+// Bots place their ships at random and differ only in how they shoot.
+type BotPlayer struct {
+	rng      *rand.Rand
+	strategy strategy
+
+	conf  field.Configuration
+	field *field.ShipField
+
+	// Fleet provided by the judge, and whether the bot was asked to load it.
+	provided []field.Ship
+	loaded   bool
+
+	opponent *knowledge
+	lastShot cell
+}
+
+func newBotPlayer(strategy strategy, seed uint64) *BotPlayer {
+	return &BotPlayer{
+		rng:      rand.New(rand.NewPCG(seed, seed)),
+		strategy: strategy,
+		conf:     DefaultConfiguration.Resized(len(DefaultConfiguration.Sizes)),
+	}
+}
+
+// Returns pointer to the configuration parameter with the given name.
+func (p *BotPlayer) confParam(name string) *int64 {
+	switch name {
+	case "width":
+		return &p.conf.W
+	case "height":
+		return &p.conf.H
+	}
+
+	var size int
+	if _, err := fmt.Sscanf(name, "count %d", &size); err != nil || size < 1 || size > field.MaxShipSize {
+		return nil
+	}
+	if size > len(p.conf.Sizes) {
+		p.conf = p.conf.Resized(size)
+	}
+	return &p.conf.Sizes[size-1]
+}
+
+func (p *BotPlayer) start() error {
+	if err := p.conf.IsValid(); err != nil {
+		return err
+	}
+
+	ships := p.provided
+	if !p.loaded {
+		var err error
+		if ships, err = field.Generate(p.conf, p.rng); err != nil {
+			return err
+		}
+	}
+
+	f := field.NewShipField(uint32(len(ships)))
+	if err := f.Load(p.conf, slices.Values(ships)); err != nil {
+		return err
+	}
+
+	p.field = f
+	p.opponent = newKnowledge(p.conf)
+
+	return nil
+}
+
+func (p *BotPlayer) handle(cmd string) (string, error) {
+	if name, ok := strings.CutPrefix(cmd, "get "); ok {
+		if param := p.confParam(name); param != nil {
+			return fmt.Sprint(*param), nil
+		}
+	}
+
+	if rest, ok := strings.CutPrefix(cmd, "set "); ok {
+		if i := strings.LastIndexByte(rest, ' '); i >= 0 {
+			if param := p.confParam(rest[:i]); param != nil {
+				_, err := fmt.Sscanf(rest[i+1:], "%d", param)
+				return "ok", err
+			}
+		}
+	}
+
+	switch {
+	case cmd == "ping":
+		return "pong", nil
+	case cmd == "exit", cmd == "stop":
+		return "ok", nil
+	case strings.HasPrefix(cmd, "create "), strings.HasPrefix(cmd, "set strategy "):
+		return "ok", nil
+	case cmd == "start":
+		return "ok", p.start()
+	case strings.HasPrefix(cmd, "load "):
+		// Field is provided in-process, there's nothing to read.
+		if p.provided == nil {
+			return "", errors.New("no field provided")
+		}
+		p.loaded = true
+		return "ok", nil
+	}
+
+	if p.field == nil {
+		return "", errors.New("game is not started")
+	}
+
+	switch {
+	case cmd == "win":
+		return yesNo(p.opponent.allSunk()), nil
+	case cmd == "lose":
+		return yesNo(p.field.AllDead()), nil
+	case cmd == "finished":
+		return yesNo(p.opponent.allSunk() || p.field.AllDead()), nil
+
+	case strings.HasPrefix(cmd, "dump "):
+		// Field is retrieved in-process, there's nothing to write.
+		return "ok", nil
+
+	case cmd == "shot":
+		p.lastShot = p.strategy(p.opponent, p.rng)
+		return fmt.Sprintf("%d %d", p.lastShot.x, p.lastShot.y), nil
+
+	case strings.HasPrefix(cmd, "shot "):
+		var x, y int64
+		if _, err := fmt.Sscanf(cmd, "shot %d %d", &x, &y); err != nil {
+			return "", err
+		}
+		return p.field.Shoot(x, y).String(), nil
+
+	case strings.HasPrefix(cmd, "set result "):
+		var result field.ShootResult
+		if err := result.FromString(strings.TrimPrefix(cmd, "set result ")); err != nil {
+			return "", err
+		}
+		p.opponent.record(p.lastShot, result)
+		return "ok", nil
+	}
+
+	return "", fmt.Errorf("unknown command: %q", cmd)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// Same as submissions, bots respond with `failed` to commands
+// they can't handle, instead of terminating.
+func (p *BotPlayer) SendCommand(cmd string) (string, error) {
+	resp, err := p.handle(cmd)
+	if err != nil {
+		return "failed", nil
+	}
+	return resp, nil
+}
+
+func (p *BotPlayer) RetrieveField(conf field.Configuration) (field.Field, error) {
+	if p.field == nil {
+		return nil, errors.New("game is not started")
+	}
+	if !p.conf.Equal(conf) {
+		return nil, errors.New("configuration mismatch")
+	}
+
+	// Judge shoots at its own copy, same as with dumped fields.
+	f := field.NewShipField(0)
+	if err := f.Load(conf, p.field.Ships()); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (p *BotPlayer) ProvideField(_ field.Configuration, f field.Field) error {
+	p.provided = slices.Collect(f.Ships())
+	return nil
+}
+
+func (p *BotPlayer) Close() error {
+	return nil
+}
+
+type PlayerFactory struct {
+	strategy strategy
+
+	// Zero means that each game is different.
+	seed uint64
+}
+
+// Returns names of all bots, sorted.
+func Names() []string {
+	return slices.Sorted(maps.Keys(strategies))
+}
+
+// Creates factory of the bot with the given name. Same non-zero seed
+// makes the bot play the same way every game.
+func NewPlayerFactory(name string, seed uint64) (*PlayerFactory, error) {
+	strategy, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown bot: %q", name)
+	}
+
+	return &PlayerFactory{
+		strategy,
+		seed,
+	}, nil
+}
+
+func (f *PlayerFactory) NewPlayer(context.Context) game.Player {
+	seed := f.seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+
+	return newBotPlayer(f.strategy, seed)
+}
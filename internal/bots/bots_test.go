@@ -0,0 +1,126 @@
+package bots_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/bots"
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+func newJudge() *judge.Judge {
+	return &judge.Judge{
+		PlayerTimeout: 10 * time.Second,
+		GlobalTimeout: 10 * time.Second,
+	}
+}
+
+func newFactory(t *testing.T, name string, seed uint64) game.PlayerFactory {
+	f, err := bots.NewPlayerFactory(name, seed)
+	require.NoError(t, err)
+	return f
+}
+
+func send(t *testing.T, p game.Player, cmd string) string {
+	resp, err := p.SendCommand(cmd)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestBots_Play(t *testing.T) {
+	for _, name := range bots.Names() {
+		t.Run(name, func(t *testing.T) {
+			verdict, transcript := newJudge().Judge(
+				context.Background(),
+				newFactory(t, name, 1),
+				newFactory(t, "random", 2),
+			)
+
+			assert.Equal(t, judge.Ok, verdict.Reason, verdict.Details)
+			assert.NotEqual(t, judge.Tie, verdict.Winner)
+			require.Len(t, transcript.Rounds, 1)
+		})
+	}
+}
+
+func TestBots_Strength(t *testing.T) {
+	series := judge.Series{
+		Judge: newJudge(),
+		Games: 20,
+	}
+
+	for _, name := range []string{"hunt-target", "parity", "density"} {
+		t.Run(name, func(t *testing.T) {
+			result, err := series.Play(context.Background(), newFactory(t, name, 0), newFactory(t, "random", 0))
+			require.NoError(t, err)
+			assert.Equal(t, judge.FirstWon, result.Winner, "score %v", result.Score)
+		})
+	}
+}
+
+func TestBots_Configurations(t *testing.T) {
+	confs := []field.Configuration{
+		{W: 1, H: 1, Sizes: []int64{1, 0, 0, 0}},
+		{W: 7, H: 1, Sizes: []int64{0, 1, 0, 1}},
+		{W: 4, H: 4, Sizes: []int64{1, 1, 1, 0}},
+		{W: 9, H: 9, Sizes: []int64{0, 0, 0, 9}},
+		{W: 1_000_000_000, H: 1_000_000_000, Sizes: []int64{1, 1, 1, 1}},
+	}
+
+	for _, conf := range confs {
+		t.Run(fmt.Sprint(conf), func(t *testing.T) {
+			p := newFactory(t, "density", 3).NewPlayer(context.Background())
+
+			assert.Equal(t, "ok", send(t, p, "create slave"))
+			assert.Equal(t, "ok", send(t, p, fmt.Sprintf("set width %d", conf.W)))
+			assert.Equal(t, "ok", send(t, p, fmt.Sprintf("set height %d", conf.H)))
+			for i, count := range conf.Sizes {
+				assert.Equal(t, "ok", send(t, p, fmt.Sprintf("set count %d %d", i+1, count)))
+			}
+			require.Equal(t, "ok", send(t, p, "start"))
+			require.Equal(t, "ok", send(t, p, "dump "+game.DumpPath))
+
+			f, err := p.RetrieveField(conf)
+			require.NoError(t, err)
+
+			// Field is valid as seen by the judge.
+			loaded := field.NewShipField(0)
+			require.NoError(t, loaded.Load(conf, f.Ships()))
+
+			// Bot doesn't shoot the same cell twice.
+			seen := make(map[string]bool)
+			for range min(conf.W*conf.H, 50) {
+				shot := send(t, p, "shot")
+				assert.False(t, seen[shot], "repeated shot %s", shot)
+				seen[shot] = true
+
+				var x, y int64
+				_, err := fmt.Sscanf(shot, "%d %d", &x, &y)
+				require.NoError(t, err)
+				require.True(t, x >= 0 && y >= 0 && x < conf.W && y < conf.H)
+
+				assert.Equal(t, "ok", send(t, p, "set result miss"))
+			}
+		})
+	}
+}
+
+func TestBots_Protocol(t *testing.T) {
+	p := newFactory(t, "random", 1).NewPlayer(context.Background())
+
+	assert.Equal(t, "ok", send(t, p, "create master"))
+	assert.Equal(t, "10", send(t, p, "get width"))
+	assert.Equal(t, "4", send(t, p, "get count 1"))
+	assert.Equal(t, "failed", send(t, p, "shot"))
+	assert.Equal(t, "failed", send(t, p, "nonsense"))
+
+	_, err := bots.NewPlayerFactory("nonexistent", 0)
+	assert.Error(t, err)
+}
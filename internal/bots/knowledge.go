@@ -0,0 +1,213 @@
+package bots
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+const (
+	// Random cells are sampled this many times before
+	// falling back to scanning the whole field.
+	sampleAttempts = 64
+
+	// Fields larger than this are never scanned.
+	maxScanCells int64 = 1 << 24
+)
+
+type cellState int
+
+const (
+	// Known to contain no ship, either shot or deduced.
+	cellEmpty cellState = iota + 1
+
+	// Hit, but the ship is not yet sunk.
+	cellHit
+
+	// Part of a sunk ship.
+	cellSunk
+)
+
+var directions = [4]cell{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+// What the shooter knows about the opponent's field.
+type knowledge struct {
+	conf field.Configuration
+
+	// Sparse, so that huge fields are fine. Absent cells are unknown.
+	cells map[cell]cellState
+
+	// Ships that are still afloat, by size.
+	remaining []int64
+
+	// Cells that are hit, but whose ships are not sunk yet.
+	hits []cell
+}
+
+func newKnowledge(conf field.Configuration) *knowledge {
+	return &knowledge{
+		conf:      conf,
+		cells:     make(map[cell]cellState),
+		remaining: slices.Clone(conf.Sizes),
+	}
+}
+
+func (k *knowledge) inBounds(c cell) bool {
+	return c.x >= 0 && c.y >= 0 && c.x < k.conf.W && c.y < k.conf.H
+}
+
+func (k *knowledge) isUnknown(c cell) bool {
+	_, known := k.cells[c]
+	return k.inBounds(c) && !known
+}
+
+// Returns the total number of cells, if it is small enough to scan.
+func (k *knowledge) scannable() (int64, bool) {
+	hi, total := bits.Mul64(uint64(k.conf.W), uint64(k.conf.H))
+	if hi != 0 || total > uint64(maxScanCells) {
+		return 0, false
+	}
+	return int64(total), true
+}
+
+func (k *knowledge) smallestRemaining() int64 {
+	for size, count := range k.remaining {
+		if count > 0 {
+			return int64(size + 1)
+		}
+	}
+	return 1
+}
+
+func (k *knowledge) allSunk() bool {
+	for _, count := range k.remaining {
+		if count > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (k *knowledge) record(c cell, result field.ShootResult) {
+	switch result {
+	case field.Miss:
+		k.cells[c] = cellEmpty
+	case field.Hit:
+		k.cells[c] = cellHit
+		k.hits = append(k.hits, c)
+	case field.Kill:
+		k.cells[c] = cellHit
+		k.sink(c)
+	}
+}
+
+// Marks the ship the cell belongs to as sunk, and cells around it as empty.
+func (k *knowledge) sink(c cell) {
+	ship := []cell{c}
+	for _, d := range directions {
+		for n := (cell{c.x + d.x, c.y + d.y}); k.cells[n] == cellHit; n = (cell{n.x + d.x, n.y + d.y}) {
+			ship = append(ship, n)
+		}
+	}
+
+	for _, s := range ship {
+		k.cells[s] = cellSunk
+
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				n := cell{s.x + dx, s.y + dy}
+				if k.isUnknown(n) {
+					k.cells[n] = cellEmpty
+				}
+			}
+		}
+	}
+
+	if size := len(ship); size <= len(k.remaining) && k.remaining[size-1] > 0 {
+		k.remaining[size-1]--
+	}
+
+	hits := k.hits[:0]
+	for _, h := range k.hits {
+		if k.cells[h] == cellHit {
+			hits = append(hits, h)
+		}
+	}
+	k.hits = hits
+}
+
+// Returns a random unknown cell satisfying the filter, if there is one.
+func (k *knowledge) randomUnknown(rng *rand.Rand, filter func(cell) bool) (cell, bool) {
+	ok := func(c cell) bool {
+		return k.isUnknown(c) && (filter == nil || filter(c))
+	}
+
+	for range sampleAttempts {
+		c := cell{rng.Int64N(k.conf.W), rng.Int64N(k.conf.H)}
+		if ok(c) {
+			return c, true
+		}
+	}
+
+	total, scannable := k.scannable()
+	if !scannable {
+		// Huge field that is almost fully known is hardly possible.
+		return cell{}, false
+	}
+
+	start := rng.Int64N(total)
+	for i := range total {
+		idx := (start + i) % total
+		c := cell{idx / k.conf.H, idx % k.conf.H}
+		if ok(c) {
+			return c, true
+		}
+	}
+
+	return cell{}, false
+}
+
+// Returns a cell adjacent to an unresolved hit, extending
+// the line of hits if its direction is already known.
+func (k *knowledge) target(rng *rand.Rand) (cell, bool) {
+	if len(k.hits) == 0 {
+		return cell{}, false
+	}
+
+	h := k.hits[0]
+
+	var candidates []cell
+	for _, axis := range [2][2]cell{{{1, 0}, {-1, 0}}, {{0, 1}, {0, -1}}} {
+		if k.cells[cell{h.x + axis[0].x, h.y + axis[0].y}] != cellHit &&
+			k.cells[cell{h.x + axis[1].x, h.y + axis[1].y}] != cellHit {
+			continue
+		}
+
+		// Line is known, so only its ends are worth shooting.
+		for _, d := range axis {
+			n := h
+			for k.cells[n] == cellHit {
+				n = cell{n.x + d.x, n.y + d.y}
+			}
+			if k.isUnknown(n) {
+				candidates = append(candidates, n)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		for _, d := range directions {
+			if n := (cell{h.x + d.x, h.y + d.y}); k.isUnknown(n) {
+				candidates = append(candidates, n)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return cell{}, false
+	}
+
+	return candidates[rng.IntN(len(candidates))], true
+}
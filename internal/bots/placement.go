@@ -0,0 +1,38 @@
+package bots
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+type cell struct {
+	x, y int64
+}
+
+// Returns source of fleets placed the same way bots place theirs,
+// e.g. for the judge to provide. Fleet depends only on the seed, the
+// configuration and the role, so that every match with the same
+// configuration gets the same fleets, no matter when it is played.
+func Placements(seed uint64) func(field.Configuration, game.Role) (field.Field, error) {
+	return func(conf field.Configuration, role game.Role) (field.Field, error) {
+		h := fnv.New64a()
+		fmt.Fprintf(h, "%s/%s", conf, role)
+
+		ships, err := field.Generate(conf, rand.New(rand.NewPCG(seed, h.Sum64())))
+		if err != nil {
+			return nil, err
+		}
+
+		f := field.NewShipField(uint32(len(ships)))
+		if err := f.Load(conf, slices.Values(ships)); err != nil {
+			return nil, err
+		}
+
+		return f, nil
+	}
+}
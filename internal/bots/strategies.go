@@ -0,0 +1,144 @@
+package bots
+
+import (
+	"math/rand/v2"
+)
+
+// Fields larger than this are too expensive to compute densities on,
+// so the density strategy plays as parity one there.
+const maxDensityCells int64 = 1 << 12
+
+// Chooses the next cell to shoot at.
+type strategy func(k *knowledge, rng *rand.Rand) cell
+
+// Falls back to any unknown cell, and to the corner if everything is known.
+func anyCell(k *knowledge, rng *rand.Rand) cell {
+	c, _ := k.randomUnknown(rng, nil)
+	return c
+}
+
+// Shoots at random unknown cells.
+func randomStrategy(k *knowledge, rng *rand.Rand) cell {
+	return anyCell(k, rng)
+}
+
+// Shoots at random until it hits a ship, then finishes it off.
+func huntTargetStrategy(k *knowledge, rng *rand.Rand) cell {
+	if c, ok := k.target(rng); ok {
+		return c
+	}
+
+	return anyCell(k, rng)
+}
+
+// Same as hunt/target, but hunts only on cells of one color of a
+// checkerboard with a period of the smallest remaining ship, as
+// every such ship covers at least one of them.
+func parityStrategy(k *knowledge, rng *rand.Rand) cell {
+	if c, ok := k.target(rng); ok {
+		return c
+	}
+
+	period := k.smallestRemaining()
+	if period > 1 {
+		onGrid := func(c cell) bool {
+			return (c.x%period+c.y%period)%period == 0
+		}
+
+		if c, ok := k.randomUnknown(rng, onGrid); ok {
+			return c
+		}
+	}
+
+	return anyCell(k, rng)
+}
+
+// Shoots at the cell covered by the most placements of remaining ships
+// that are consistent with what is known. If there are unresolved hits,
+// only placements covering them are considered.
+func densityStrategy(k *knowledge, rng *rand.Rand) cell {
+	total, ok := k.scannable()
+	if !ok || total > maxDensityCells {
+		return parityStrategy(k, rng)
+	}
+
+	W, H := k.conf.W, k.conf.H
+	density := make([]int64, total)
+	targeting := len(k.hits) > 0
+
+	for size := int64(1); size <= int64(len(k.remaining)); size++ {
+		count := k.remaining[size-1]
+		if count == 0 {
+			continue
+		}
+
+		for vertical := range 2 {
+			// Single cell ships have only one orientation.
+			if size == 1 && vertical == 1 {
+				continue
+			}
+
+			dx, dy := int64(1), int64(0)
+			if vertical == 1 {
+				dx, dy = 0, 1
+			}
+
+			for x := int64(0); x+dx*(size-1) < W; x++ {
+				for y := int64(0); y+dy*(size-1) < H; y++ {
+					var hits int64
+					valid := true
+
+					for i := range size {
+						switch k.cells[cell{x + dx*i, y + dy*i}] {
+						case cellEmpty, cellSunk:
+							valid = false
+						case cellHit:
+							hits++
+						}
+					}
+
+					if !valid || (targeting && hits == 0) {
+						continue
+					}
+
+					// Placements explaining more hits are much more likely.
+					weight := count << (4 * min(hits, 8))
+
+					for i := range size {
+						c := cell{x + dx*i, y + dy*i}
+						if k.isUnknown(c) {
+							density[c.x*H+c.y] += weight
+						}
+					}
+				}
+			}
+		}
+	}
+
+	best, bestDensity, ties := int64(-1), int64(0), 0
+	for idx, d := range density {
+		switch {
+		case d > bestDensity:
+			best, bestDensity, ties = int64(idx), d, 1
+		case d == bestDensity && d > 0:
+			// Reservoir sampling among equally good cells.
+			ties++
+			if rng.IntN(ties) == 0 {
+				best = int64(idx)
+			}
+		}
+	}
+
+	if best < 0 {
+		return huntTargetStrategy(k, rng)
+	}
+
+	return cell{best / H, best % H}
+}
+
+var strategies = map[string]strategy{
+	"random":      randomStrategy,
+	"hunt-target": huntTargetStrategy,
+	"parity":      parityStrategy,
+	"density":     densityStrategy,
+}
@@ -0,0 +1,368 @@
+package conformance
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+// Configuration slaves are set up with. It differs from the usual
+// one and isn't square, so that ignored or swapped values are caught.
+var configuration = field.Configuration{
+	W:     12,
+	H:     9,
+	Sizes: []int64{3, 2, 2, 1},
+}
+
+// Field slaves are asked to `load`, matching the configuration.
+var fleet = []field.Ship{
+	{X: 0, Y: 0, Size: 4},
+	{X: 0, Y: 2, Size: 3},
+	{X: 5, Y: 2, Size: 3},
+	{X: 0, Y: 4, Size: 2},
+	{X: 4, Y: 4, Size: 2},
+	{X: 0, Y: 6, Size: 1},
+	{X: 2, Y: 6, Size: 1},
+	{X: 11, Y: 8, Size: 1},
+}
+
+var Checks = []Check{
+	{
+		Name:        "create-master",
+		Description: "`create master` and `set strategy custom` reply `ok`",
+		Run: func(p *game.PlayerExt) error {
+			return create(p, game.RoleMaster)
+		},
+	},
+	{
+		Name:        "create-slave",
+		Description: "`create slave` and `set strategy custom` reply `ok`",
+		Run: func(p *game.PlayerExt) error {
+			return create(p, game.RoleSlave)
+		},
+	},
+	{
+		Name:        "master-configuration",
+		Description: "master replies to `get` with a valid configuration, and dumps a field matching it",
+		Run:         checkMasterConfiguration,
+	},
+	{
+		Name:        "set-get",
+		Description: "values passed to `set` are returned by `get`",
+		Run:         checkSetGet,
+	},
+	{
+		Name:        "dump",
+		Description: "slave replies `ok` to `dump` and writes a valid field matching the configuration",
+		Run: func(p *game.PlayerExt) error {
+			_, err := startSlave(p, configuration)
+			return err
+		},
+	},
+	{
+		Name:        "load",
+		Description: "slave replies `ok` to `load` and plays with the loaded field, dumping it back",
+		Run:         checkLoad,
+		Optional:    true,
+	},
+	{
+		Name:        "shot-results",
+		Description: "`shot X Y` is answered with `miss`, `hit` or `kill`, according to the dumped field",
+		Run:         checkShotResults,
+	},
+	{
+		Name:        "shooting",
+		Description: "`shot` is answered with coordinates on the field, and every `set result` with `ok`",
+		Run:         checkShooting,
+	},
+	{
+		Name:        "lose",
+		Description: "`win` and `lose` reply `no` during the game, and `lose` replies `yes` once all ships are sunk",
+		Run:         checkLose,
+	},
+	{
+		Name:        "win",
+		Description: "`win` replies `yes` once all opponent's ships are sunk",
+		Run:         checkWin,
+	},
+}
+
+// Sends the command, failing if the response is not the expected one.
+func expect(p *game.PlayerExt, cmd, want string) error {
+	resp, err := p.SendCommand(cmd)
+	if err != nil {
+		return fmt.Errorf("%q failed: %w", cmd, err)
+	}
+	if resp != want {
+		return fmt.Errorf("%q returned %q instead of %q", cmd, resp, want)
+	}
+	return nil
+}
+
+func confParams(conf *field.Configuration) []struct {
+	name string
+	val  *int64
+} {
+	params := []struct {
+		name string
+		val  *int64
+	}{
+		{"width", &conf.W},
+		{"height", &conf.H},
+	}
+
+	for i := range conf.Sizes {
+		params = append(params, struct {
+			name string
+			val  *int64
+		}{fmt.Sprintf("count %d", i+1), &conf.Sizes[i]})
+	}
+
+	return params
+}
+
+func create(p *game.PlayerExt, role game.Role) error {
+	if err := expect(p, "create "+role.String(), "ok"); err != nil {
+		return err
+	}
+	return expect(p, "set strategy custom", "ok")
+}
+
+// Creates slave with the given configuration, starts it
+// and returns the field it has dumped.
+func startSlave(p *game.PlayerExt, conf field.Configuration) (field.Field, error) {
+	if err := create(p, game.RoleSlave); err != nil {
+		return nil, err
+	}
+
+	for _, param := range confParams(&conf) {
+		if err := expect(p, fmt.Sprintf("set %s %d", param.name, *param.val), "ok"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := expect(p, "start", "ok"); err != nil {
+		return nil, err
+	}
+
+	f, err := p.RequestAndGetField(conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get field: %w", err)
+	}
+
+	return f, nil
+}
+
+// Shoots at the player, expecting the same result as on the field.
+func shoot(p *game.PlayerExt, f field.Field, x, y int64) error {
+	return expect(p, fmt.Sprintf("shot %d %d", x, y), f.Shoot(x, y).String())
+}
+
+// Shoots at every cell of the ship, from one end to the other.
+func sink(p *game.PlayerExt, f field.Field, ship field.Ship) error {
+	for i := range int64(ship.Size) {
+		x, y := ship.X+i, ship.Y
+		if ship.IsVert {
+			x, y = ship.X, ship.Y+i
+		}
+
+		if err := shoot(p, f, x, y); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkMasterConfiguration(p *game.PlayerExt) error {
+	if err := create(p, game.RoleMaster); err != nil {
+		return err
+	}
+
+	conf := field.Configuration{Sizes: make([]int64, field.DefaultMaxShipSize)}
+	for _, param := range confParams(&conf) {
+		if err := p.SendScanf("get "+param.name, "%d", param.val); err != nil {
+			return fmt.Errorf("%q failed: %w", "get "+param.name, err)
+		}
+	}
+
+	if err := conf.IsValid(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := expect(p, "start", "ok"); err != nil {
+		return err
+	}
+
+	if _, err := p.RequestAndGetField(conf); err != nil {
+		return fmt.Errorf("failed to get field: %w", err)
+	}
+
+	return nil
+}
+
+func checkSetGet(p *game.PlayerExt) error {
+	if err := create(p, game.RoleSlave); err != nil {
+		return err
+	}
+
+	conf := configuration
+	params := confParams(&conf)
+
+	for _, param := range params {
+		if err := expect(p, fmt.Sprintf("set %s %d", param.name, *param.val), "ok"); err != nil {
+			return err
+		}
+	}
+
+	for _, param := range params {
+		if err := expect(p, "get "+param.name, fmt.Sprint(*param.val)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkLoad(p *game.PlayerExt) error {
+	if err := create(p, game.RoleSlave); err != nil {
+		return err
+	}
+
+	conf := configuration
+	for _, param := range confParams(&conf) {
+		if err := expect(p, fmt.Sprintf("set %s %d", param.name, *param.val), "ok"); err != nil {
+			return err
+		}
+	}
+
+	provided := field.NewShipField(0)
+	if err := provided.Load(conf, slices.Values(fleet)); err != nil {
+		return err
+	}
+	if err := p.ProvideField(conf, provided); err != nil {
+		return fmt.Errorf("failed to provide field: %w", err)
+	}
+
+	if err := expect(p, "load "+game.LoadPath, "ok"); err != nil {
+		return err
+	}
+	if err := expect(p, "start", "ok"); err != nil {
+		return err
+	}
+
+	f, err := p.RequestAndGetField(conf)
+	if err != nil {
+		return fmt.Errorf("failed to get field: %w", err)
+	}
+
+	for _, ship := range fleet {
+		if err := sink(p, f, ship); err != nil {
+			return fmt.Errorf("field differs from the loaded one: %w", err)
+		}
+	}
+
+	return expect(p, "lose", "yes")
+}
+
+func checkShotResults(p *game.PlayerExt) error {
+	f, err := startSlave(p, configuration)
+	if err != nil {
+		return err
+	}
+
+	for _, ship := range slices.Collect(f.Ships()) {
+		// Cells just before and after the ship are always empty.
+		dx, dy := int64(1), int64(0)
+		if ship.IsVert {
+			dx, dy = 0, 1
+		}
+
+		for _, i := range []int64{-1, int64(ship.Size)} {
+			x, y := ship.X+dx*i, ship.Y+dy*i
+			if x < 0 || y < 0 || x >= configuration.W || y >= configuration.H {
+				continue
+			}
+			if err := shoot(p, f, x, y); err != nil {
+				return err
+			}
+		}
+
+		if err := sink(p, f, ship); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkShooting(p *game.PlayerExt) error {
+	if _, err := startSlave(p, configuration); err != nil {
+		return err
+	}
+
+	for _, result := range []field.ShootResult{field.Miss, field.Miss, field.Hit, field.Kill, field.Miss} {
+		var x, y int64
+		if err := p.SendScanf("shot", "%d %d", &x, &y); err != nil {
+			return fmt.Errorf("%q failed: %w", "shot", err)
+		}
+
+		if x < 0 || y < 0 || x >= configuration.W || y >= configuration.H {
+			return fmt.Errorf("shot %d %d is outside of the field", x, y)
+		}
+
+		if err := expect(p, "set result "+result.String(), "ok"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkLose(p *game.PlayerExt) error {
+	f, err := startSlave(p, configuration)
+	if err != nil {
+		return err
+	}
+
+	if err := expect(p, "win", "no"); err != nil {
+		return err
+	}
+	if err := expect(p, "lose", "no"); err != nil {
+		return err
+	}
+
+	for _, ship := range slices.Collect(f.Ships()) {
+		if err := sink(p, f, ship); err != nil {
+			return err
+		}
+	}
+
+	if err := expect(p, "lose", "yes"); err != nil {
+		return err
+	}
+	return expect(p, "win", "no")
+}
+
+func checkWin(p *game.PlayerExt) error {
+	// The only possible shot sinks the only ship.
+	conf := field.Configuration{W: 1, H: 1, Sizes: []int64{1, 0, 0, 0}}
+
+	if _, err := startSlave(p, conf); err != nil {
+		return err
+	}
+
+	if err := expect(p, "shot", "0 0"); err != nil {
+		return err
+	}
+	if err := expect(p, "set result kill", "ok"); err != nil {
+		return err
+	}
+
+	if err := expect(p, "win", "yes"); err != nil {
+		return err
+	}
+	return expect(p, "lose", "no")
+}
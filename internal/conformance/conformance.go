@@ -0,0 +1,105 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/game/field/render"
+)
+
+var errCheckTimeout error = errors.New("check timeout")
+
+// Scripted scenario a player has to go through.
+type Check struct {
+	Name        string
+	Description string
+
+	// Drives a fresh player through the scenario, returning
+	// the first deviation from the protocol, if there is one.
+	Run func(p *game.PlayerExt) error
+
+	// Set for parts of the protocol that only some modes require,
+	// e.g. `load`. Failing them doesn't fail the report.
+	Optional bool
+}
+
+type CheckResult struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Passed      bool   `json:"passed"`
+	Optional    bool   `json:"optional,omitempty"`
+	Details     string `json:"details,omitempty"`
+
+	// Last field the player has dumped during the check, drawn
+	// as ASCII, so that it's clear what the dump contained.
+	Field string `json:"field,omitempty"`
+}
+
+type Report struct {
+	// Whether all checks have passed, except for optional ones.
+	Passed bool          `json:"passed"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Remembers the last field the player has dumped.
+type fieldRecorder struct {
+	game.Player
+	conf  field.Configuration
+	field field.Field
+}
+
+func (p *fieldRecorder) RetrieveField(conf field.Configuration) (field.Field, error) {
+	f, err := p.Player.RetrieveField(conf)
+	if err == nil {
+		p.conf, p.field = conf, f
+	}
+	return f, err
+}
+
+func (p *fieldRecorder) render() string {
+	if p.field == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	render.New(p.conf, p.field, nil).WriteASCII(&sb)
+	return sb.String()
+}
+
+// Runs every check against its own player, each given at most
+// `timeout` of player time. Checks keep running after failures,
+// so that all of them are reported at once.
+func Run(ctx context.Context, factory game.PlayerFactory, timeout time.Duration) Report {
+	swFactory := game.NewStopwatchPlayerFactory(factory, timeout, errCheckTimeout)
+
+	report := Report{Passed: true}
+
+	for _, check := range Checks {
+		result := CheckResult{
+			Name:        check.Name,
+			Description: check.Description,
+			Passed:      true,
+			Optional:    check.Optional,
+		}
+
+		player := &fieldRecorder{Player: swFactory.NewPlayer(ctx)}
+		err := check.Run(&game.PlayerExt{Player: player})
+		player.Close()
+
+		result.Field = player.render()
+
+		if err != nil {
+			result.Passed = false
+			result.Details = err.Error()
+			report.Passed = report.Passed && check.Optional
+		}
+
+		report.Checks = append(report.Checks, result)
+	}
+
+	return report
+}
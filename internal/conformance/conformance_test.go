@@ -0,0 +1,114 @@
+package conformance_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/bots"
+	"github.com/mrsobakin/itmournament/internal/conformance"
+	"github.com/mrsobakin/itmournament/internal/game"
+)
+
+// Replaces responses to some commands, leaving the rest to the bot.
+type brokenPlayer struct {
+	game.Player
+	overrides map[string]string
+}
+
+func (p *brokenPlayer) SendCommand(cmd string) (string, error) {
+	if resp, ok := p.overrides[cmd]; ok {
+		return resp, nil
+	}
+	return p.Player.SendCommand(cmd)
+}
+
+type brokenFactory struct {
+	factory   game.PlayerFactory
+	overrides map[string]string
+}
+
+func (f *brokenFactory) NewPlayer(ctx context.Context) game.Player {
+	return &brokenPlayer{f.factory.NewPlayer(ctx), f.overrides}
+}
+
+func failed(report conformance.Report) []string {
+	var names []string
+	for _, check := range report.Checks {
+		if !check.Passed {
+			names = append(names, check.Name)
+		}
+	}
+	return names
+}
+
+func TestConformance_Bots(t *testing.T) {
+	for _, name := range bots.Names() {
+		t.Run(name, func(t *testing.T) {
+			factory, err := bots.NewPlayerFactory(name, 0)
+			require.NoError(t, err)
+
+			report := conformance.Run(context.Background(), factory, time.Second)
+			assert.True(t, report.Passed, "%+v", report.Checks)
+			assert.Len(t, report.Checks, len(conformance.Checks))
+		})
+	}
+}
+
+func TestConformance_Broken(t *testing.T) {
+	tests := []struct {
+		overrides map[string]string
+		failed    []string
+	}{
+		{map[string]string{"lose": "no"}, []string{"load", "lose"}},
+		{map[string]string{"set result kill": "failed"}, []string{"shooting", "win"}},
+		{map[string]string{"get height": "12"}, []string{"master-configuration", "set-get"}},
+		{map[string]string{"create master": "okay"}, []string{"create-master", "master-configuration"}},
+		{map[string]string{"dump " + game.DumpPath: "failed"}, []string{"master-configuration", "dump", "load", "shot-results", "shooting", "lose", "win"}},
+	}
+
+	for _, test := range tests {
+		factory, err := bots.NewPlayerFactory("random", 1)
+		require.NoError(t, err)
+
+		report := conformance.Run(context.Background(), &brokenFactory{factory, test.overrides}, time.Second)
+
+		assert.False(t, report.Passed)
+		assert.Equal(t, test.failed, failed(report), test.overrides)
+	}
+}
+
+// Player that doesn't support `load` still passes, as it's optional.
+func TestConformance_Optional(t *testing.T) {
+	factory, err := bots.NewPlayerFactory("random", 1)
+	require.NoError(t, err)
+
+	broken := &brokenFactory{factory, map[string]string{"load " + game.LoadPath: "failed"}}
+	report := conformance.Run(context.Background(), broken, time.Second)
+
+	assert.True(t, report.Passed)
+	assert.Equal(t, []string{"load"}, failed(report))
+}
+
+func TestConformance_Fields(t *testing.T) {
+	factory, err := bots.NewPlayerFactory("random", 1)
+	require.NoError(t, err)
+
+	report := conformance.Run(context.Background(), factory, time.Second)
+
+	for _, check := range report.Checks {
+		switch check.Name {
+		case "create-master", "create-slave", "set-get":
+			assert.Empty(t, check.Field, check.Name)
+		case "dump":
+			// Slaves are set up with a 12x9 field.
+			lines := strings.Split(strings.TrimSuffix(check.Field, "\n"), "\n")
+			assert.Len(t, lines, 9)
+			assert.Len(t, lines[0], 2*12-1)
+		}
+	}
+}
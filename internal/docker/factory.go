@@ -0,0 +1,29 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+)
+
+type PlayerFactory struct {
+	runner  *SubmissionRunner
+	imageId string
+}
+
+func NewPlayerFactory(runner *SubmissionRunner, imageId string) *PlayerFactory {
+	return &PlayerFactory{
+		runner,
+		imageId,
+	}
+}
+
+func (f *PlayerFactory) NewPlayer(ctx context.Context) game.Player {
+	p, err := NewDockerPlayer(f.runner, ctx, f.imageId)
+	if err != nil {
+		return &game.FailedPlayer{Err: fmt.Errorf("failed to create player: %w", err)}
+	}
+
+	return p
+}
@@ -2,6 +2,7 @@ package docker
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 
@@ -73,23 +74,34 @@ func (p *DockerPlayer) SendCommand(cmd string) (string, error) {
 }
 
 func (p *DockerPlayer) RetrieveField(conf field.Configuration) (field.Field, error) {
-	r, err := p.cont.ReadFile("/tmp/field.txt")
+	r, err := p.cont.ReadFile(game.DumpPath)
 
 	if err != nil {
 		return nil, convertContainerErrToPlayerErr(err)
 	}
 
 	f := field.NewShipField(0)
-
-	ships := field.ParseShips(r)
-	err = f.Load(conf, ships)
-	if err != nil {
+	if err := field.ReadShips(f, r, conf); err != nil {
 		return nil, err
 	}
 
 	return f, nil
 }
 
+func (p *DockerPlayer) ProvideField(conf field.Configuration, f field.Field) error {
+	var buf bytes.Buffer
+	if err := field.WriteShips(&buf, conf, f.Ships()); err != nil {
+		return err
+	}
+
+	err := p.cont.WriteFile(game.LoadPath, buf.Bytes())
+	if err != nil {
+		return convertContainerErrToPlayerErr(err)
+	}
+
+	return nil
+}
+
 func (p *DockerPlayer) Close() error {
 	return p.cont.Close()
 }
@@ -84,7 +84,7 @@ func Test_GetField(t *testing.T) {
 	f, err := player.RetrieveField(field.Configuration{
 		W:     10,
 		H:     10,
-		Sizes: [4]int64{1, 1, 1, 1},
+		Sizes: []int64{1, 1, 1, 1},
 	})
 	require.NoError(t, err)
 
@@ -119,7 +119,7 @@ func Test_GetField_MemoryLimit(t *testing.T) {
 	f, err := player.RetrieveField(field.Configuration{
 		W:     10,
 		H:     10,
-		Sizes: [4]int64{1, 1, 1, 1},
+		Sizes: []int64{1, 1, 1, 1},
 	})
 	assert.ErrorIs(t, err, &game.ErrorTerminated{Reason: game.ReasonMemoryLimit})
 	assert.Nil(t, f)
@@ -1,9 +1,12 @@
 package docker
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -164,6 +167,45 @@ func (c *SubmissionContainer) ReadFile(path string) (io.ReadCloser, error) {
 	return untar, nil
 }
 
+// Writes the file into the container, readable by everyone.
+func (c *SubmissionContainer) WriteFile(path string, data []byte) error {
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+	err := tw.WriteHeader(&tar.Header{
+		Name: filepath.Base(path),
+		Mode: 0644,
+		Size: int64(len(data)),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	err = c.runner.cli.CopyToContainer(c.ctx, c.id, filepath.Dir(path), &buf, container.CopyToContainerOptions{})
+
+	if err != nil {
+		if !client.IsErrNotFound(err) {
+			return err
+		}
+
+		// Same dirty hack as in `ReadFile`
+		if !strings.HasPrefix(err.Error(), "Error response from daemon: No such container: ") {
+			return err
+		}
+
+		result := c.Wait()
+		return &ErrorTerminated{result}
+	}
+
+	return nil
+}
+
 func (c *SubmissionContainer) Wait() RunResult {
 	if !c.started.Load() {
 		return RunResult{0, fmt.Errorf("container was never started")}
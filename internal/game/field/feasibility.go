@@ -0,0 +1,228 @@
+package field
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"time"
+)
+
+// Outcome of `CheckFeasibility`.
+type Feasibility int
+
+const (
+	// Neither a placement nor a proof that there is none was found in time.
+	FeasibilityUnknown Feasibility = iota
+	Feasible
+	Infeasible
+)
+
+func (f Feasibility) String() string {
+	switch f {
+	case Feasible:
+		return "feasible"
+	case Infeasible:
+		return "infeasible"
+	default:
+		return "unknown"
+	}
+}
+
+// Wrapped by errors telling why ships of a configuration can't be placed
+// at all. Wraps `ErrCantPlace` in turn.
+var ErrInfeasible error = fmt.Errorf("%w, no matter how", ErrCantPlace)
+
+// Tells whether ships of the configuration can be placed on its field.
+//
+// Placement is proven possible by packing ships into every other row,
+// and impossible by bounds on the room ships take. If neither works,
+// ships are placed at random until the budget runs out, after which
+// `FeasibilityUnknown` is returned. Same configuration and budget may
+// still give different outcomes, depending on how fast the machine is.
+//
+// Error is returned only for infeasible configurations, telling why,
+// and wraps `ErrInfeasible`.
+func CheckFeasibility(conf Configuration, budget time.Duration) (Feasibility, error) {
+	deadline := time.Now().Add(budget)
+
+	if err := conf.IsValid(); err != nil {
+		return Infeasible, fmt.Errorf("%w: %w", ErrInfeasible, err)
+	}
+
+	if err := checkBounds(conf); err != nil {
+		return Infeasible, err
+	}
+
+	if fitsRows(conf, false) || fitsRows(conf, true) {
+		return Feasible, nil
+	}
+
+	sizes, err := shipSizes(conf)
+	if err != nil {
+		return FeasibilityUnknown, nil
+	}
+
+	if _, ok := pack(conf, sizes); ok {
+		return Feasible, nil
+	}
+
+	rng := rand.New(rand.NewPCG(0, 0))
+
+attempts:
+	for time.Now().Before(deadline) {
+		occ := make(occupancy)
+
+		for _, size := range sizes {
+			if _, ok := occ.placeRandom(conf, size, rng); !ok || time.Now().After(deadline) {
+				continue attempts
+			}
+		}
+
+		return Feasible, nil
+	}
+
+	return FeasibilityUnknown, nil
+}
+
+// Fails if ships can't be placed no matter what.
+func checkBounds(conf Configuration) error {
+	if size := conf.MaxSize(); int64(size) > max(conf.W, conf.H) {
+		return fmt.Errorf("%w: ship of size %d doesn't fit %dx%d field", ErrInfeasible, size, conf.W, conf.H)
+	}
+
+	var area, blocks big.Int
+	for i, count := range conf.Sizes {
+		size := int64(i + 1)
+		area.Add(&area, new(big.Int).Mul(big.NewInt(count), big.NewInt(2*(size+1))))
+		blocks.Add(&blocks, new(big.Int).Mul(big.NewInt(count), big.NewInt((size+1)/2)))
+	}
+
+	w, h := big.NewInt(conf.W), big.NewInt(conf.H)
+	one := big.NewInt(1)
+
+	// Ships, each extended by a cell to the right and to the bottom,
+	// don't overlap and fit into the field extended the same way.
+	fieldArea := new(big.Int).Mul(new(big.Int).Add(w, one), new(big.Int).Add(h, one))
+	if area.Cmp(fieldArea) > 0 {
+		return fmt.Errorf("%w: ships take more room than %dx%d field has", ErrInfeasible, conf.W, conf.H)
+	}
+
+	// All cells of a 2x2 block touch each other, so a block holds decks
+	// of one ship at most, and a ship of size N spans (N+1)/2 blocks at least.
+	half := func(x *big.Int) *big.Int {
+		return new(big.Int).Rsh(new(big.Int).Add(x, one), 1)
+	}
+	if blocks.Cmp(new(big.Int).Mul(half(w), half(h))) > 0 {
+		return fmt.Errorf("%w: ships can't be kept apart on %dx%d field", ErrInfeasible, conf.W, conf.H)
+	}
+
+	return nil
+}
+
+// Reports whether ships fit every other row (or column) when rows are
+// given to each size of ships separately. Unlike `pack`, works for any
+// number of ships, as they are never laid out one by one.
+func fitsRows(conf Configuration, vertical bool) bool {
+	w, h := conf.W, conf.H
+	if vertical {
+		w, h = h, w
+	}
+
+	free := uint64(h/2 + h%2)
+
+	var rows uint64
+	for i, count := range conf.Sizes {
+		if count == 0 {
+			continue
+		}
+
+		// Each ship takes a cell more than its size, for the gap after
+		// it, except for the last one in the row.
+		perRow := uint64(w/int64(i+2) + w%int64(i+2)/int64(i+1))
+		if perRow == 0 {
+			return false
+		}
+
+		rows += (uint64(count)-1)/perRow + 1
+		if rows > free {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Row (or column) of `pack` with some room left after its ships.
+type shelf struct {
+	x, y int64
+}
+
+// Places ships largest first into every other row (or column), putting
+// each one into the row that has the least room left for it. This fits
+// even configurations too tight for random placement.
+func packShips(conf Configuration, sizes []int8, vertical bool) ([]Ship, bool) {
+	w, h := conf.W, conf.H
+	if vertical {
+		w, h = h, w
+	}
+
+	ships := make([]Ship, 0, len(sizes))
+	place := func(x, y int64, size int8) {
+		ship := Ship{X: x, Y: y, Size: size}
+		if vertical {
+			ship = Ship{X: y, Y: x, Size: size, IsVert: size > 1}
+		}
+		ships = append(ships, ship)
+	}
+
+	// Ship takes a cell more than its size, for the gap after it, and
+	// so does the row. Rows with less room than the largest ship needs
+	// are kept by the room left, the rest is the row being filled.
+	var short [MaxShipSize + 2][]shelf
+	width := min(w, math.MaxInt64-1) + 1
+	var x, y int64
+	room := width
+
+ships:
+	for _, size := range sizes {
+		need := int64(size) + 1
+
+		for left := need; left < int64(len(short)); left++ {
+			if n := len(short[left]); n > 0 {
+				s := short[left][n-1]
+				short[left] = short[left][:n-1]
+
+				place(s.x, s.y, size)
+				if left-need >= 2 {
+					short[left-need] = append(short[left-need], shelf{s.x + need, s.y})
+				}
+				continue ships
+			}
+		}
+
+		if need > room {
+			if room >= 2 {
+				short[room] = append(short[room], shelf{x, y})
+			}
+			x, y, room = 0, y+2, width
+		}
+		if y >= h || need > room {
+			return nil, false
+		}
+
+		place(x, y, size)
+		x, room = x+need, room-need
+	}
+
+	return ships, true
+}
+
+func pack(conf Configuration, sizes []int8) ([]Ship, bool) {
+	for _, vertical := range []bool{false, true} {
+		if ships, ok := packShips(conf, sizes, vertical); ok {
+			return ships, true
+		}
+	}
+	return nil, false
+}
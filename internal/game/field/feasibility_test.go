@@ -0,0 +1,100 @@
+package field_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+func TestCheckFeasibility(t *testing.T) {
+	cases := []struct {
+		conf   field.Configuration
+		result field.Feasibility
+	}{
+		{field.Configuration{W: 10, H: 10, Sizes: []int64{4, 3, 2, 1}}, field.Feasible},
+		{field.Configuration{W: 1, H: 1, Sizes: []int64{1}}, field.Feasible},
+		{field.Configuration{W: 7, H: 1, Sizes: []int64{0, 1, 0, 1}}, field.Feasible},
+		{field.Configuration{W: 4, H: 4, Sizes: []int64{4}}, field.Feasible},
+		{field.Configuration{W: 500, H: 500, Sizes: []int64{14800, 11100, 7400, 3700}}, field.Feasible},
+		{field.Configuration{W: math.MaxInt64, H: math.MaxInt64, Sizes: []int64{1 << 40, 1 << 40}}, field.Feasible},
+
+		{field.Configuration{W: 1, H: 1, Sizes: []int64{0, 0, 0, 10}}, field.Infeasible},
+		{field.Configuration{W: 3, H: 3, Sizes: []int64{0, 0, 0, 1}}, field.Infeasible},
+		{field.Configuration{W: 7, H: 1, Sizes: []int64{0, 1, 0, 2}}, field.Infeasible},
+		{field.Configuration{W: 4, H: 4, Sizes: []int64{5}}, field.Infeasible},
+		{field.Configuration{W: 10, H: 10, Sizes: []int64{0, 0, 0, 0}}, field.Infeasible},
+		{field.Configuration{W: 10, H: 10, Sizes: []int64{1 << 40, 1 << 40}}, field.Infeasible},
+	}
+
+	for _, c := range cases {
+		result, err := field.CheckFeasibility(c.conf, 0)
+		assert.Equal(t, c.result, result, c.conf.String())
+
+		if c.result == field.Infeasible {
+			assert.ErrorIs(t, err, field.ErrInfeasible, c.conf.String())
+		} else {
+			assert.NoError(t, err, c.conf.String())
+		}
+	}
+}
+
+func TestCheckFeasibility_Reasons(t *testing.T) {
+	_, err := field.CheckFeasibility(field.Configuration{W: 3, H: 2, Sizes: []int64{0, 0, 0, 1}}, 0)
+	assert.ErrorContains(t, err, "ship of size 4 doesn't fit 3x2 field")
+
+	_, err = field.CheckFeasibility(field.Configuration{W: 10, H: 10, Sizes: []int64{0, 0, 0, 20}}, 0)
+	assert.ErrorContains(t, err, "ships take more room than 10x10 field has")
+
+	_, err = field.CheckFeasibility(field.Configuration{W: 4, H: 4, Sizes: []int64{5}}, 0)
+	assert.ErrorContains(t, err, "ships can't be kept apart on 4x4 field")
+}
+
+// Tight configurations that packing can't prove are left to random
+// placement, and stay unknown if there is no time for it.
+func TestCheckFeasibility_Budget(t *testing.T) {
+	// 2 2 . 2
+	// . . . 2
+	// 2 . . .
+	// 2 . 2 2
+	conf := field.Configuration{W: 4, H: 4, Sizes: []int64{0, 4}}
+
+	result, err := field.CheckFeasibility(conf, 0)
+	require.NoError(t, err)
+	assert.Equal(t, field.FeasibilityUnknown, result)
+
+	result, err = field.CheckFeasibility(conf, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, field.Feasible, result)
+}
+
+// Whatever is proven infeasible is never generated, and whatever
+// is proven feasible always is.
+func TestCheckFeasibility_Fuzzy(t *testing.T) {
+	rng := newRng(2)
+
+	for range 500 {
+		conf := field.Configuration{
+			W:     rng.Int64N(10) + 1,
+			H:     rng.Int64N(10) + 1,
+			Sizes: make([]int64, rng.IntN(5)+1),
+		}
+		for i := range conf.Sizes {
+			conf.Sizes[i] = rng.Int64N(5)
+		}
+
+		result, _ := field.CheckFeasibility(conf, 0)
+		_, err := field.Generate(conf, rng)
+
+		switch result {
+		case field.Feasible:
+			assert.NoError(t, err, conf.String())
+		case field.Infeasible:
+			assert.Error(t, err, conf.String())
+		}
+	}
+}
@@ -2,11 +2,18 @@ package field
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"iter"
+	"strconv"
+	"strings"
 )
 
+// Wrapped by errors of `Field.Load` caused by the field itself.
+var ErrInvalidField error = errors.New("invalid field")
+
 type ShootResult int
 
 const (
@@ -42,9 +49,76 @@ func (r ShootResult) String() string {
 	}
 }
 
+func (r ShootResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+func (r *ShootResult) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	return r.FromString(str)
+}
+
+const (
+	// Largest ship size of the classic game.
+	DefaultMaxShipSize = 4
+
+	// Largest ship size a field can hold at all, bounded by decks
+	// packed into ship data.
+	MaxShipSize = 56
+)
+
 type Configuration struct {
-	W, H  int64
-	Sizes [4]int64
+	W, H int64
+
+	// Number of ships of each size, starting from 1. Sizes past
+	// the end of the slice have no ships.
+	Sizes []int64
+}
+
+// Returns the number of ships of the given size.
+func (c Configuration) Count(size int) int64 {
+	if size < 1 || size > len(c.Sizes) {
+		return 0
+	}
+	return c.Sizes[size-1]
+}
+
+// Returns the largest size that has ships, or 0 if there are none.
+func (c Configuration) MaxSize() int {
+	for size := len(c.Sizes); size > 0; size-- {
+		if c.Sizes[size-1] != 0 {
+			return size
+		}
+	}
+	return 0
+}
+
+// Returns copy of the configuration with exactly `n` sizes,
+// dropping or adding sizes past the end.
+func (c Configuration) Resized(n int) Configuration {
+	sizes := make([]int64, n)
+	copy(sizes, c.Sizes)
+	c.Sizes = sizes
+	return c
+}
+
+// Reports whether configurations are the same, regardless
+// of sizes without ships past the end.
+func (c Configuration) Equal(other Configuration) bool {
+	if c.W != other.W || c.H != other.H {
+		return false
+	}
+
+	for size := 1; size <= max(len(c.Sizes), len(other.Sizes)); size++ {
+		if c.Count(size) != other.Count(size) {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (c *Configuration) IsValid() error {
@@ -52,21 +126,78 @@ func (c *Configuration) IsValid() error {
 		return fmt.Errorf("non-positive field size: [%d %d]", c.W, c.H)
 	}
 
-	if c.Sizes[0] < 0 || c.Sizes[1] < 0 || c.Sizes[2] < 0 || c.Sizes[3] < 0 {
-		return fmt.Errorf("negative ship amount: [%d %d %d %d]", c.Sizes[0], c.Sizes[1], c.Sizes[2], c.Sizes[3])
+	var total int64
+	for _, count := range c.Sizes {
+		if count < 0 {
+			return fmt.Errorf("negative ship amount: %v", c.Sizes)
+		}
+		total += count
 	}
 
-	if (c.Sizes[0] + c.Sizes[1] + c.Sizes[2] + c.Sizes[3]) <= 0 {
-		return fmt.Errorf("summary ship count is non-positive: [%d %d %d %d]", c.Sizes[0], c.Sizes[1], c.Sizes[2], c.Sizes[3])
+	if total <= 0 {
+		return fmt.Errorf("summary ship count is non-positive: %v", c.Sizes)
+	}
+
+	if size := c.MaxSize(); size > MaxShipSize {
+		return fmt.Errorf("ship size %d exceeds maximum of %d", size, MaxShipSize)
 	}
 
 	return nil
 }
 
+// Formats configuration as `WxH:N1,N2,...`, where Ni is
+// the number of ships of size i, e.g. `10x10:4,3,2,1`.
+func (c Configuration) String() string {
+	counts := make([]string, len(c.Sizes))
+	for i, count := range c.Sizes {
+		counts[i] = strconv.FormatInt(count, 10)
+	}
+	return fmt.Sprintf("%dx%d:%s", c.W, c.H, strings.Join(counts, ","))
+}
+
+// Parses configuration formatted as by `Configuration.String`.
+// Sizes without ships past the last listed one may be omitted.
+func ParseConfiguration(s string) (Configuration, error) {
+	var c Configuration
+
+	dims, counts, ok := strings.Cut(s, ":")
+	if !ok {
+		return c, fmt.Errorf("invalid configuration %q, expected WxH:N1,N2,...", s)
+	}
+
+	w, h, ok := strings.Cut(dims, "x")
+	if !ok {
+		return c, fmt.Errorf("invalid field size %q, expected WxH", dims)
+	}
+
+	var err error
+	if c.W, err = strconv.ParseInt(w, 10, 64); err != nil {
+		return c, fmt.Errorf("invalid width: %w", err)
+	}
+	if c.H, err = strconv.ParseInt(h, 10, 64); err != nil {
+		return c, fmt.Errorf("invalid height: %w", err)
+	}
+
+	sizes := strings.Split(counts, ",")
+	if len(sizes) > MaxShipSize {
+		return c, fmt.Errorf("too many ship sizes: %d", len(sizes))
+	}
+
+	c.Sizes = make([]int64, len(sizes))
+	for i, count := range sizes {
+		if c.Sizes[i], err = strconv.ParseInt(count, 10, 64); err != nil {
+			return c, fmt.Errorf("invalid count of size %d: %w", i+1, err)
+		}
+	}
+
+	return c, nil
+}
+
 type Ship struct {
-	X, Y   int64
-	Size   int8
-	IsVert bool
+	X      int64 `json:"x"`
+	Y      int64 `json:"y"`
+	Size   int8  `json:"size"`
+	IsVert bool  `json:"vertical"`
 }
 
 type Field interface {
@@ -88,8 +219,14 @@ type Field interface {
 	// Returns whether all ships are destroyed, i.e. the
 	// corresponding player lost.
 	AllDead() bool
+
+	// Returns all ships on the field, in no particular order.
+	Ships() iter.Seq[Ship]
 }
 
+// Parses ships dumped by a player, one per line, after the first
+// line with field dimensions. Stops silently at the first line that
+// can't be parsed, see `ShipReader` for the strict version.
 func ParseShips(src io.Reader) iter.Seq[Ship] {
 	return func(yield func(s Ship) bool) {
 		lines := bufio.NewScanner(src)
@@ -122,3 +259,205 @@ func ParseShips(src io.Reader) iter.Seq[Ship] {
 		}
 	}
 }
+
+// Error of `ShipReader`, pointing at the offending line of the dump.
+type DumpError struct {
+	// Line number, starting from 1, and its content.
+	Line    int
+	Content string
+
+	Err error
+}
+
+func (e *DumpError) Error() string {
+	return fmt.Sprintf("%v on line %d: %q", e.Err, e.Line, e.Content)
+}
+
+func (e *DumpError) Unwrap() error {
+	return e.Err
+}
+
+// Dumps with longer lines are rejected, and those lines are
+// truncated in errors.
+const (
+	maxDumpLine  = 1 << 10
+	maxShownLine = 64
+)
+
+// Parses ships dumped by a player for the configuration, strictly. The
+// first line must hold the field dimensions of the configuration, and
+// every other line a ship as `size h|v x y`. Blank lines are skipped.
+//
+// Ships are checked to be within the field and to be no more than the
+// configuration has, but not to be apart, which is left to `Field.Load`.
+// Ships are parsed as they are iterated over, so `Err` is set only
+// once `Ships` is done.
+type ShipReader struct {
+	lines *bufio.Scanner
+	conf  Configuration
+	err   error
+
+	// Line of the ship yielded last, for errors found by `Field.Load`.
+	line    int
+	content string
+}
+
+func NewShipReader(src io.Reader, conf Configuration) *ShipReader {
+	lines := bufio.NewScanner(src)
+	lines.Buffer(make([]byte, 0, 64), maxDumpLine)
+
+	return &ShipReader{lines: lines, conf: conf}
+}
+
+// Yields ships until the dump ends or turns out to be invalid. Can be
+// iterated over only once.
+func (r *ShipReader) Ships() iter.Seq[Ship] {
+	return func(yield func(Ship) bool) {
+		r.err = r.read(yield)
+	}
+}
+
+// Returns why `Ships` stopped short, if the dump is invalid. Errors wrap
+// `ErrInvalidField`, and are `*DumpError` if caused by some line in
+// particular.
+func (r *ShipReader) Err() error {
+	return r.err
+}
+
+func shownLine(line string) string {
+	if len(line) > maxShownLine {
+		return line[:maxShownLine] + "..."
+	}
+	return line
+}
+
+func (r *ShipReader) read(yield func(Ship) bool) error {
+	conf := r.conf
+	counts := make([]int64, len(conf.Sizes))
+	header := false
+
+	for n := 1; r.lines.Scan(); n++ {
+		line := r.lines.Text()
+		fail := func(format string, args ...any) error {
+			return &DumpError{n, shownLine(line), fmt.Errorf("%w: %s", ErrInvalidField, fmt.Sprintf(format, args...))}
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if !header {
+			header = true
+
+			if len(fields) != 2 {
+				return fail("expected field dimensions as `W H`")
+			}
+			w, errW := strconv.ParseInt(fields[0], 10, 64)
+			h, errH := strconv.ParseInt(fields[1], 10, 64)
+			if errW != nil || errH != nil {
+				return fail("invalid field dimensions")
+			}
+			if w != conf.W || h != conf.H {
+				return fail("field is %dx%d, but configuration is %dx%d", w, h, conf.W, conf.H)
+			}
+			continue
+		}
+
+		if len(fields) != 4 {
+			return fail("expected ship as `size h|v x y`")
+		}
+
+		size, err := strconv.ParseInt(fields[0], 10, 8)
+		if err != nil || size < 1 {
+			return fail("invalid ship size %q", fields[0])
+		}
+
+		ship := Ship{Size: int8(size)}
+		switch fields[1] {
+		case "v":
+			ship.IsVert = true
+		case "h":
+		default:
+			return fail("invalid direction %q, expected `h` or `v`", fields[1])
+		}
+
+		if ship.X, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+			return fail("invalid x %q", fields[2])
+		}
+		if ship.Y, err = strconv.ParseInt(fields[3], 10, 64); err != nil {
+			return fail("invalid y %q", fields[3])
+		}
+
+		w, h := int64(ship.Size), int64(1)
+		if ship.IsVert {
+			w, h = h, w
+		}
+		if ship.X < 0 || ship.Y < 0 || ship.X > conf.W-w || ship.Y > conf.H-h {
+			return fail("ship out of bounds of %dx%d field", conf.W, conf.H)
+		}
+
+		if size > int64(len(counts)) || counts[size-1] == conf.Sizes[size-1] {
+			return fail("configuration has only %d ships of size %d", conf.Count(int(size)), size)
+		}
+		counts[size-1]++
+
+		r.line, r.content = n, line
+		if !yield(ship) {
+			return nil
+		}
+	}
+
+	if err := r.lines.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("%w: line longer than %d bytes", ErrInvalidField, maxDumpLine)
+		}
+		return err
+	}
+
+	if !header {
+		return fmt.Errorf("%w: dump is empty", ErrInvalidField)
+	}
+
+	for i, count := range counts {
+		if count != conf.Sizes[i] {
+			return fmt.Errorf("%w: %d ships of size %d, but configuration has %d", ErrInvalidField, count, i+1, conf.Sizes[i])
+		}
+	}
+
+	return nil
+}
+
+// Loads ships dumped by a player into the field as they are parsed by
+// `ShipReader`. Errors of the dump take precedence over those of `Load`,
+// which are `*DumpError` pointing at the ship `Load` has stopped at.
+func ReadShips(f Field, src io.Reader, conf Configuration) error {
+	r := NewShipReader(src, conf)
+
+	err := f.Load(conf, r.Ships())
+	if r.Err() != nil {
+		return r.Err()
+	}
+	if err != nil && r.line > 0 {
+		return &DumpError{r.line, shownLine(r.content), err}
+	}
+	return err
+}
+
+// Writes ships in the format read by `ShipReader`, with the
+// field dimensions on the first line.
+func WriteShips(w io.Writer, conf Configuration, ships iter.Seq[Ship]) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "%d %d\n", conf.W, conf.H)
+
+	for ship := range ships {
+		direction := 'h'
+		if ship.IsVert {
+			direction = 'v'
+		}
+		fmt.Fprintf(bw, "%d %c %d %d\n", ship.Size, direction, ship.X, ship.Y)
+	}
+
+	return bw.Flush()
+}
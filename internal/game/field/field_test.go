@@ -5,6 +5,7 @@ import (
 	_ "embed"
 	"math/rand"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,7 +26,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     10,
 			H:     10,
-			Sizes: [4]int64{1, 1, 0, 0},
+			Sizes: []int64{1, 1, 0, 0},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -42,7 +43,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     5,
 			H:     5,
-			Sizes: [4]int64{2, 0, 1, 0},
+			Sizes: []int64{2, 0, 1, 0},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -61,7 +62,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     3,
 			H:     3,
-			Sizes: [4]int64{0, 0, 0, 1},
+			Sizes: []int64{0, 0, 0, 1},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -80,7 +81,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     3,
 			H:     3,
-			Sizes: [4]int64{1, 0, 0, 0},
+			Sizes: []int64{1, 0, 0, 0},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -101,7 +102,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     5,
 			H:     5,
-			Sizes: [4]int64{0, 0, 0, 2},
+			Sizes: []int64{0, 0, 0, 2},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -121,7 +122,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     3,
 			H:     3,
-			Sizes: [4]int64{2, 0, 0, 0},
+			Sizes: []int64{2, 0, 0, 0},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -143,7 +144,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     5,
 			H:     5,
-			Sizes: [4]int64{0, 1, 1, 0},
+			Sizes: []int64{0, 1, 1, 0},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -165,7 +166,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     5,
 			H:     5,
-			Sizes: [4]int64{0, 1, 1, 0},
+			Sizes: []int64{0, 1, 1, 0},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -185,7 +186,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     3,
 			H:     3,
-			Sizes: [4]int64{2, 0, 0, 0},
+			Sizes: []int64{2, 0, 0, 0},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -207,7 +208,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     5,
 			H:     5,
-			Sizes: [4]int64{0, 1, 1, 0},
+			Sizes: []int64{0, 1, 1, 0},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -224,7 +225,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 	// . . .
 	t.Run("Shoot_EmptyCell", func(t *testing.T) {
 		f := newField()
-		conf := field.Configuration{W: 3, H: 3, Sizes: [4]int64{0, 0, 0, 0}}
+		conf := field.Configuration{W: 3, H: 3, Sizes: []int64{0, 0, 0, 0}}
 		require.NoError(t, f.Load(conf, slices.Values([]field.Ship{})))
 
 		result := f.Shoot(1, 1)
@@ -239,7 +240,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     3,
 			H:     3,
-			Sizes: [4]int64{1, 0, 0, 0},
+			Sizes: []int64{1, 0, 0, 0},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -260,7 +261,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     3,
 			H:     3,
-			Sizes: [4]int64{1, 0, 0, 0},
+			Sizes: []int64{1, 0, 0, 0},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -284,7 +285,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     3,
 			H:     3,
-			Sizes: [4]int64{0, 1, 0, 0},
+			Sizes: []int64{0, 1, 0, 0},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -308,7 +309,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     6,
 			H:     6,
-			Sizes: [4]int64{1, 2, 1, 2},
+			Sizes: []int64{1, 2, 1, 2},
 		}
 
 		ships := slices.Values([]field.Ship{
@@ -374,7 +375,7 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		conf := field.Configuration{
 			W:     500,
 			H:     500,
-			Sizes: [4]int64{14800, 11100, 7400, 3700},
+			Sizes: []int64{14800, 11100, 7400, 3700},
 		}
 
 		ships := field.ParseShips(bytes.NewReader(txtFuzzField))
@@ -414,13 +415,31 @@ func RunFieldTests(t *testing.T, newField func() field.Field) {
 		assert.Equal(t, nExpectedHits, nHits)
 	})
 
+	t.Run("Ships_MatchLoaded", func(t *testing.T) {
+		f := newField()
+		conf := field.Configuration{
+			W:     10,
+			H:     10,
+			Sizes: []int64{2, 8, 3, 4},
+		}
+		ships := slices.Collect(field.ParseShips(bytes.NewReader(txtDenseField)))
+
+		require.NoError(t, f.Load(conf, slices.Values(ships)))
+		assert.ElementsMatch(t, ships, slices.Collect(f.Ships()))
+
+		// Shots should not affect ships layout.
+		f.Shoot(0, 0)
+		f.Shoot(9, 9)
+		assert.ElementsMatch(t, ships, slices.Collect(f.Ships()))
+	})
+
 	// Dense field is a such field, that moving any ship
 	// anywhere but its original position makes it invalid.
 	t.Run("Load_Dense", func(t *testing.T) {
 		conf := field.Configuration{
 			W:     10,
 			H:     10,
-			Sizes: [4]int64{2, 8, 3, 4},
+			Sizes: []int64{2, 8, 3, 4},
 		}
 		shipsOriginal := slices.Collect(field.ParseShips(bytes.NewReader(txtDenseField)))
 
@@ -464,3 +483,209 @@ func TestShipField(t *testing.T) {
 		return field.NewShipField(0)
 	})
 }
+
+func TestParseConfiguration(t *testing.T) {
+	conf := field.Configuration{W: 12, H: 9, Sizes: []int64{4, 3, 2, 1}}
+	assert.Equal(t, "12x9:4,3,2,1", conf.String())
+
+	parsed, err := field.ParseConfiguration(conf.String())
+	require.NoError(t, err)
+	assert.Equal(t, conf, parsed)
+
+	parsed, err = field.ParseConfiguration("1000000000x3:0,5")
+	require.NoError(t, err)
+	assert.Equal(t, field.Configuration{W: 1_000_000_000, H: 3, Sizes: []int64{0, 5}}, parsed)
+
+	parsed, err = field.ParseConfiguration("20x20:1,2,3,4,5")
+	require.NoError(t, err)
+	assert.Equal(t, 5, parsed.MaxSize())
+
+	tooMany := "10x10:" + strings.Repeat("0,", field.MaxShipSize) + "1"
+	for _, invalid := range []string{"", "10x10", "10:1", "ax10:1", tooMany, "10x10:1,,2", "10x10 :1"} {
+		_, err := field.ParseConfiguration(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestWriteShips(t *testing.T) {
+	conf := field.Configuration{W: 10, H: 10, Sizes: []int64{2, 8, 3, 4}}
+	ships := slices.Collect(field.ParseShips(bytes.NewReader(txtDenseField)))
+
+	var buf bytes.Buffer
+	require.NoError(t, field.WriteShips(&buf, conf, slices.Values(ships)))
+
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("10 10\n")))
+	assert.Equal(t, ships, slices.Collect(field.ParseShips(&buf)))
+}
+
+func TestShipReader(t *testing.T) {
+	conf := field.Configuration{W: 10, H: 10, Sizes: []int64{2, 8, 3, 4}}
+	ships := slices.Collect(field.ParseShips(bytes.NewReader(txtDenseField)))
+
+	// Drawing of the field after the ships isn't part of the dump.
+	dump, _, _ := bytes.Cut(txtDenseField, []byte("\n\n"))
+
+	r := field.NewShipReader(bytes.NewReader(dump), conf)
+	assert.Equal(t, ships, slices.Collect(r.Ships()))
+	require.NoError(t, r.Err())
+
+	r = field.NewShipReader(bytes.NewReader(txtDenseField), conf)
+	assert.Equal(t, ships, slices.Collect(r.Ships()))
+	assert.ErrorContains(t, r.Err(), "on line 20")
+
+	var buf bytes.Buffer
+	require.NoError(t, field.WriteShips(&buf, conf, slices.Values(ships)))
+
+	r = field.NewShipReader(&buf, conf)
+	assert.Equal(t, ships, slices.Collect(r.Ships()))
+	require.NoError(t, r.Err())
+}
+
+func TestShipReader_Streams(t *testing.T) {
+	conf := field.Configuration{W: 5, H: 4, Sizes: []int64{2, 1}}
+
+	// Ships before the invalid line are yielded as they are read.
+	r := field.NewShipReader(strings.NewReader("5 4\n1 h 0 0\n1 h 2 0\n1 h 4 0\n"), conf)
+	assert.Equal(t, []field.Ship{{X: 0, Y: 0, Size: 1}, {X: 2, Y: 0, Size: 1}}, slices.Collect(r.Ships()))
+	assert.ErrorIs(t, r.Err(), field.ErrInvalidField)
+
+	// Stopping early isn't an error.
+	r = field.NewShipReader(strings.NewReader("5 4\n1 h 0 0\n1 h 2 0\n"), conf)
+	for range r.Ships() {
+		break
+	}
+	assert.NoError(t, r.Err())
+}
+
+func TestReadShips(t *testing.T) {
+	conf := field.Configuration{W: 5, H: 4, Sizes: []int64{2, 1}}
+
+	f := field.NewShipField(0)
+	require.NoError(t, field.ReadShips(f, strings.NewReader("5 4\n1 h 0 0\n1 h 2 0\n2 v 4 1\n"), conf))
+	assert.Equal(t, field.Kill, f.Shoot(0, 0))
+
+	// Ships too close are left to `Load`, but still point at the line.
+	err := field.ReadShips(field.NewShipField(0), strings.NewReader("5 4\n1 h 0 0\n\n1 h 1 1\n2 v 4 1\n"), conf)
+	assert.ErrorIs(t, err, field.ErrInvalidField)
+	assert.ErrorContains(t, err, "ships overlap")
+
+	var dumpErr *field.DumpError
+	if assert.ErrorAs(t, err, &dumpErr) {
+		assert.Equal(t, 4, dumpErr.Line)
+		assert.Equal(t, "1 h 1 1", dumpErr.Content)
+	}
+}
+
+func TestReadShips_Errors(t *testing.T) {
+	conf := field.Configuration{W: 5, H: 4, Sizes: []int64{2, 1}}
+
+	cases := []struct {
+		dump    string
+		line    int
+		message string
+	}{
+		{"5 5\n", 1, "field is 5x5, but configuration is 5x4"},
+		{"5\n", 1, "expected field dimensions"},
+		{"five 4\n", 1, "invalid field dimensions"},
+		{"5 4\n1 h 0 0\n1 x 2 0\n", 3, `invalid direction "x"`},
+		{"5 4\n\n1 h 0 0\n\n2 h 0 2 extra\n", 5, "expected ship as"},
+		{"5 4\n0 h 0 0\n", 2, `invalid ship size "0"`},
+		{"5 4\n1 h a 0\n", 2, `invalid x "a"`},
+		{"5 4\n2 h 4 0\n", 2, "ship out of bounds"},
+		{"5 4\n2 v 0 3\n", 2, "ship out of bounds"},
+		{"5 4\n1 h 0 0\n1 h 2 0\n1 h 4 0\n", 4, "configuration has only 2 ships of size 1"},
+		{"5 4\n3 h 0 0\n", 2, "configuration has only 0 ships of size 3"},
+	}
+
+	for _, c := range cases {
+		err := field.ReadShips(field.NewShipField(0), strings.NewReader(c.dump), conf)
+		assert.ErrorIs(t, err, field.ErrInvalidField, c.dump)
+		assert.ErrorContains(t, err, c.message, c.dump)
+
+		var dumpErr *field.DumpError
+		if assert.ErrorAs(t, err, &dumpErr, c.dump) {
+			assert.Equal(t, c.line, dumpErr.Line, c.dump)
+		}
+	}
+
+	err := field.ReadShips(field.NewShipField(0), strings.NewReader(""), conf)
+	assert.ErrorIs(t, err, field.ErrInvalidField)
+	assert.ErrorContains(t, err, "dump is empty")
+
+	err = field.ReadShips(field.NewShipField(0), strings.NewReader("5 4\n1 h 0 0\n2 h 0 2\n"), conf)
+	assert.ErrorIs(t, err, field.ErrInvalidField)
+	assert.ErrorContains(t, err, "1 ships of size 1, but configuration has 2")
+
+	err = field.ReadShips(field.NewShipField(0), strings.NewReader("5 4\n"+strings.Repeat("1", 1<<12)+"\n"), conf)
+	assert.ErrorIs(t, err, field.ErrInvalidField)
+}
+
+func TestConfiguration_Equal(t *testing.T) {
+	conf := field.Configuration{W: 10, H: 10, Sizes: []int64{4, 3, 2, 1}}
+
+	assert.True(t, conf.Equal(conf.Resized(7)))
+	assert.True(t, conf.Resized(7).Equal(conf))
+	assert.False(t, conf.Equal(conf.Resized(3)))
+	assert.False(t, conf.Equal(field.Configuration{W: 10, H: 9, Sizes: conf.Sizes}))
+
+	assert.Equal(t, 4, conf.Resized(7).MaxSize())
+	assert.Equal(t, int64(0), conf.Count(5))
+}
+
+func TestShipField_LongShips(t *testing.T) {
+	conf := field.Configuration{W: 12, H: 12, Sizes: []int64{1, 0, 0, 0, 1, 0, 1}}
+
+	f := field.NewShipField(0)
+	require.NoError(t, f.Load(conf, slices.Values([]field.Ship{
+		{X: 0, Y: 0, Size: 7},
+		{X: 11, Y: 2, Size: 5, IsVert: true},
+		{X: 0, Y: 11, Size: 1},
+	})))
+
+	for x := range int64(6) {
+		assert.Equal(t, field.Hit, f.Shoot(x, 0))
+	}
+	assert.Equal(t, field.Kill, f.Shoot(6, 0))
+
+	for y := range int64(4) {
+		assert.Equal(t, field.Hit, f.Shoot(11, 2+y))
+	}
+	assert.Equal(t, field.Miss, f.Shoot(11, 7))
+	assert.Equal(t, field.Kill, f.Shoot(11, 6))
+
+	assert.False(t, f.AllDead())
+	assert.Equal(t, field.Kill, f.Shoot(0, 11))
+	assert.True(t, f.AllDead())
+
+	// Ship touching the end of the long ship, far from its start.
+	f = field.NewShipField(0)
+	err := f.Load(conf, slices.Values([]field.Ship{
+		{X: 0, Y: 0, Size: 7},
+		{X: 7, Y: 1, Size: 5, IsVert: true},
+		{X: 0, Y: 11, Size: 1},
+	}))
+	assert.ErrorIs(t, err, field.ErrInvalidField)
+
+	// Ship longer than the configuration allows.
+	f = field.NewShipField(0)
+	err = f.Load(conf, slices.Values([]field.Ship{{X: 0, Y: 0, Size: 8}}))
+	assert.ErrorIs(t, err, field.ErrInvalidField)
+}
+
+func TestShipField_LargestShip(t *testing.T) {
+	sizes := make([]int64, field.MaxShipSize)
+	sizes[field.MaxShipSize-1] = 1
+	conf := field.Configuration{W: 3, H: field.MaxShipSize, Sizes: sizes}
+
+	f := field.NewShipField(0)
+	require.NoError(t, f.Load(conf, slices.Values([]field.Ship{
+		{X: 1, Y: 0, Size: field.MaxShipSize, IsVert: true},
+	})))
+
+	for y := range int64(field.MaxShipSize - 1) {
+		assert.Equal(t, field.Hit, f.Shoot(1, y))
+	}
+	assert.False(t, f.AllDead())
+	assert.Equal(t, field.Kill, f.Shoot(1, field.MaxShipSize-1))
+	assert.True(t, f.AllDead())
+}
@@ -0,0 +1,157 @@
+package field
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+)
+
+const (
+	// Configurations with more ships are not generated at all,
+	// as the field would take too much memory anyway.
+	maxGeneratedShips int64 = 1 << 20
+
+	// Random placement is retried up to this many times, but with no more
+	// than the budget of ships placed overall, so that large fleets fail fast.
+	placementAttempts = 32
+	placementBudget   = 1 << 15
+	shipAttempts      = 200
+)
+
+// Wrapped by errors of `Generate`.
+var ErrCantPlace error = errors.New("can't place ships")
+
+type cell struct {
+	x, y int64
+}
+
+// Returns sizes of all ships in the configuration, largest first.
+func shipSizes(conf Configuration) ([]int8, error) {
+	var total int64
+	for _, count := range conf.Sizes {
+		if count > maxGeneratedShips-total {
+			return nil, fmt.Errorf("%w: more than %d ships", ErrCantPlace, maxGeneratedShips)
+		}
+		total += count
+	}
+
+	sizes := make([]int8, 0, total)
+	for size := len(conf.Sizes); size >= 1; size-- {
+		for range conf.Sizes[size-1] {
+			sizes = append(sizes, int8(size))
+		}
+	}
+
+	return sizes, nil
+}
+
+func shipCells(ship Ship, yield func(cell)) {
+	for i := range int64(ship.Size) {
+		if ship.IsVert {
+			yield(cell{ship.X, ship.Y + i})
+		} else {
+			yield(cell{ship.X + i, ship.Y})
+		}
+	}
+}
+
+// Sparse set of occupied cells, so that huge fields are fine.
+type occupancy map[cell]struct{}
+
+// Checks that the ship doesn't touch any other ship, diagonally included.
+func (o occupancy) fits(ship Ship) bool {
+	w, h := int64(ship.Size), int64(1)
+	if ship.IsVert {
+		w, h = h, w
+	}
+
+	for x := ship.X - 1; x <= ship.X+w; x++ {
+		for y := ship.Y - 1; y <= ship.Y+h; y++ {
+			if _, ok := o[cell{x, y}]; ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (o occupancy) add(ship Ship) {
+	shipCells(ship, func(c cell) {
+		o[c] = struct{}{}
+	})
+}
+
+// Tries to place the ship at a random position.
+func (o occupancy) placeRandom(conf Configuration, size int8, rng *rand.Rand) (Ship, bool) {
+	for range shipAttempts {
+		ship := Ship{
+			Size:   size,
+			IsVert: size > 1 && rng.IntN(2) == 1,
+		}
+
+		w, h := int64(size), int64(1)
+		if ship.IsVert {
+			w, h = h, w
+		}
+		if w > conf.W || h > conf.H {
+			continue
+		}
+
+		ship.X = rng.Int64N(conf.W - w + 1)
+		ship.Y = rng.Int64N(conf.H - h + 1)
+
+		if o.fits(ship) {
+			o.add(ship)
+			return ship, true
+		}
+	}
+
+	return Ship{}, false
+}
+
+// Places ships of the configuration at random, so that they don't touch
+// each other and are accepted by `ShipField.Load`. Falls back to packing
+// ships densely if random placement fails. Same state of the generator
+// gives the same placement.
+//
+// Errors wrap `ErrCantPlace`, and also `ErrInfeasible` if the
+// configuration is proven impossible to place.
+func Generate(conf Configuration, rng *rand.Rand) ([]Ship, error) {
+	if err := conf.IsValid(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCantPlace, err)
+	}
+
+	if err := checkBounds(conf); err != nil {
+		return nil, err
+	}
+
+	sizes, err := shipSizes(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := min(placementAttempts, max(1, placementBudget/len(sizes)))
+
+attempts:
+	for range attempts {
+		occ := make(occupancy)
+		ships := make([]Ship, 0, len(sizes))
+
+		for _, size := range sizes {
+			ship, ok := occ.placeRandom(conf, size, rng)
+			if !ok {
+				continue attempts
+			}
+			ships = append(ships, ship)
+		}
+
+		return ships, nil
+	}
+
+	if ships, ok := pack(conf, sizes); ok {
+		return ships, nil
+	}
+
+	return nil, fmt.Errorf("%w: no placement found", ErrCantPlace)
+}
@@ -0,0 +1,81 @@
+package field_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+func newRng(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+func TestGenerate(t *testing.T) {
+	confs := []field.Configuration{
+		{W: 1, H: 1, Sizes: []int64{1}},
+		{W: 10, H: 10, Sizes: []int64{4, 3, 2, 1}},
+		{W: 7, H: 1, Sizes: []int64{0, 1, 0, 1}},
+		{W: 9, H: 9, Sizes: []int64{0, 0, 0, 9}},
+		{W: 12, H: 12, Sizes: []int64{1, 0, 0, 0, 1, 0, 1}},
+		{W: 500, H: 500, Sizes: []int64{14800, 11100, 7400, 3700}},
+		{W: 1_000_000_000, H: 1_000_000_000, Sizes: []int64{1000, 1000, 1000, 1000}},
+	}
+
+	for _, conf := range confs {
+		t.Run(conf.String(), func(t *testing.T) {
+			ships, err := field.Generate(conf, newRng(1))
+			require.NoError(t, err)
+
+			f := field.NewShipField(uint32(len(ships)))
+			require.NoError(t, f.Load(conf, slices.Values(ships)))
+
+			again, err := field.Generate(conf, newRng(1))
+			require.NoError(t, err)
+			assert.Equal(t, ships, again)
+		})
+	}
+}
+
+func TestGenerate_Infeasible(t *testing.T) {
+	confs := []field.Configuration{
+		{W: 1, H: 1, Sizes: []int64{0, 0, 0, 10}},
+		{W: 3, H: 3, Sizes: []int64{0, 0, 0, 1}},
+		{W: 3, H: 3, Sizes: []int64{5}},
+		{W: 10, H: 10, Sizes: []int64{0, 0, 0, 0}},
+	}
+
+	for _, conf := range confs {
+		_, err := field.Generate(conf, newRng(1))
+		assert.ErrorIs(t, err, field.ErrCantPlace, conf.String())
+	}
+}
+
+// Whatever is generated for random configurations is accepted by `Load`.
+func TestGenerate_Fuzzy(t *testing.T) {
+	rng := newRng(1)
+
+	for range 500 {
+		conf := field.Configuration{
+			W:     rng.Int64N(12) + 1,
+			H:     rng.Int64N(12) + 1,
+			Sizes: make([]int64, rng.IntN(6)+1),
+		}
+		for i := range conf.Sizes {
+			conf.Sizes[i] = rng.Int64N(4)
+		}
+
+		ships, err := field.Generate(conf, rng)
+		if err != nil {
+			assert.ErrorIs(t, err, field.ErrCantPlace)
+			continue
+		}
+
+		f := field.NewShipField(0)
+		assert.NoError(t, f.Load(conf, slices.Values(ships)), conf.String())
+	}
+}
@@ -0,0 +1,318 @@
+// Package render draws fields, along with the shots made at them,
+// as ASCII grids and SVG images.
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+// Fields with a longer side can't be sensibly drawn cell by cell,
+// and are downsampled to a heatmap of ship density instead.
+const MaxSide int64 = 100
+
+type Shot struct {
+	X, Y int64
+
+	// Result of the shot, used only if the field is unknown.
+	Result field.ShootResult
+}
+
+type cellState uint8
+
+const (
+	cellEmpty cellState = iota
+	cellMiss
+	cellDeck
+	cellHit
+	cellKill
+)
+
+type cell struct {
+	state cellState
+	size  int8
+}
+
+type pos struct {
+	x, y int64
+}
+
+// Field prepared for rendering.
+type Board struct {
+	// Shown above the board in SVG images.
+	Title string
+
+	// Size of the board in drawn cells, and the number of field
+	// cells along each side of a drawn cell, which is 1 unless
+	// the board is a heatmap.
+	W, H  int64
+	Scale int64
+
+	cells []cell
+
+	// Number of all and of destroyed decks in each drawn cell,
+	// relative to the largest number of decks in one.
+	decks, damaged []float64
+}
+
+// Lays out ships of the field and shots made at it. Field may be nil if
+// it is unknown, in which case results of the shots are shown as is.
+func New(conf field.Configuration, f field.Field, shots []Shot) *Board {
+	cells := layout(conf, f, shots)
+
+	scale := int64(1)
+	if side := max(conf.W, conf.H); side > MaxSide {
+		scale = (side-1)/MaxSide + 1
+	}
+
+	b := &Board{
+		W:     (conf.W-1)/scale + 1,
+		H:     (conf.H-1)/scale + 1,
+		Scale: scale,
+	}
+
+	if scale == 1 {
+		b.cells = make([]cell, b.W*b.H)
+		for p, c := range cells {
+			b.cells[p.y*b.W+p.x] = c
+		}
+		return b
+	}
+
+	b.decks = make([]float64, b.W*b.H)
+	b.damaged = make([]float64, b.W*b.H)
+
+	for p, c := range cells {
+		idx := (p.y/scale)*b.W + p.x/scale
+		if c.state == cellHit || c.state == cellKill {
+			b.damaged[idx]++
+		}
+		if c.state != cellMiss {
+			b.decks[idx]++
+		}
+	}
+
+	var most float64
+	for _, decks := range b.decks {
+		most = max(most, decks)
+	}
+	if most > 0 {
+		for i := range b.decks {
+			b.decks[i] /= most
+			b.damaged[i] /= most
+		}
+	}
+
+	return b
+}
+
+// Returns states of all cells that aren't empty. Field is walked
+// ship by ship, so that fields of any size are fine.
+func layout(conf field.Configuration, f field.Field, shots []Shot) map[pos]cell {
+	cells := make(map[pos]cell)
+	inside := func(p pos) bool {
+		return p.x >= 0 && p.y >= 0 && p.x < conf.W && p.y < conf.H
+	}
+
+	var ships []field.Ship
+	if f != nil {
+		ships = slices.Collect(f.Ships())
+	}
+
+	// Index of the ship occupying the cell.
+	owners := make(map[pos]int)
+	hits := make([]int8, len(ships))
+
+	for i, ship := range ships {
+		for deck := range int64(ship.Size) {
+			p := pos{ship.X, ship.Y}
+			if ship.IsVert {
+				p.y += deck
+			} else {
+				p.x += deck
+			}
+
+			if inside(p) {
+				cells[p] = cell{cellDeck, ship.Size}
+				owners[p] = i
+			}
+		}
+	}
+
+	for _, shot := range shots {
+		p := pos{shot.X, shot.Y}
+		if !inside(p) {
+			continue
+		}
+
+		c := cells[p]
+		switch {
+		case c.state == cellDeck:
+			c.state = cellHit
+			hits[owners[p]]++
+		case c.state != cellEmpty:
+			// Repeated shot.
+		case f != nil || shot.Result == field.Miss:
+			c.state = cellMiss
+		case shot.Result == field.Hit:
+			c.state = cellHit
+		case shot.Result == field.Kill:
+			c.state = cellKill
+		}
+		cells[p] = c
+	}
+
+	for p, owner := range owners {
+		if c := cells[p]; c.state == cellHit && hits[owner] == ships[owner].Size {
+			cells[p] = cell{cellKill, c.size}
+		}
+	}
+
+	return cells
+}
+
+// Returns whether the board is downsampled to a heatmap.
+func (b *Board) IsHeatmap() bool {
+	return b.Scale > 1
+}
+
+// Heatmap shades, from the fewest decks to the most.
+const asciiShades = ".:-=+*#%@"
+
+func (b *Board) symbol(idx int64) byte {
+	if b.IsHeatmap() {
+		if b.decks[idx] == 0 {
+			return ' '
+		}
+		shade := math.Ceil(b.decks[idx]*float64(len(asciiShades))) - 1
+		return asciiShades[int(shade)]
+	}
+
+	c := b.cells[idx]
+	switch c.state {
+	case cellMiss:
+		return 'o'
+	case cellDeck:
+		if c.size > 9 {
+			return '#'
+		}
+		return '0' + byte(c.size)
+	case cellHit:
+		return 'x'
+	case cellKill:
+		return 'X'
+	default:
+		return '.'
+	}
+}
+
+// Writes the board as an ASCII grid.
+//
+// Intact decks are shown with the size of their ship, misses as `o`,
+// hit decks as `x` and decks of killed ships as `X`. Heatmaps show
+// the number of decks, from `.` for the fewest to `@` for the most.
+func (b *Board) WriteASCII(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	for y := range b.H {
+		for x := range b.W {
+			if x != 0 {
+				bw.WriteByte(' ')
+			}
+			bw.WriteByte(b.symbol(y*b.W + x))
+		}
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
+
+const (
+	svgCell   = 16
+	svgMargin = 24
+)
+
+var svgColors = map[cellState]string{
+	cellEmpty: "#f4f7fb",
+	cellMiss:  "#f4f7fb",
+	cellDeck:  "#8a94a6",
+	cellHit:   "#f0a030",
+	cellKill:  "#d03030",
+}
+
+func (b *Board) writeSVG(w *bufio.Writer, offsetX int64) {
+	fmt.Fprintf(w, `<g transform="translate(%d %d)">`+"\n", offsetX, svgMargin)
+	fmt.Fprintf(w, `<text x="0" y="-8" font-family="monospace" font-size="14">%s</text>`+"\n", b.title())
+	fmt.Fprintf(w, `<rect width="%d" height="%d" fill="%s" stroke="#445"/>`+"\n", b.W*svgCell, b.H*svgCell, svgColors[cellEmpty])
+
+	for y := range b.H {
+		for x := range b.W {
+			idx := y*b.W + x
+			px, py := x*svgCell, y*svgCell
+
+			if b.IsHeatmap() {
+				for _, layer := range []struct {
+					state   cellState
+					opacity float64
+				}{{cellDeck, b.decks[idx]}, {cellKill, b.damaged[idx]}} {
+					if layer.opacity > 0 {
+						fmt.Fprintf(w, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" fill-opacity="%.2f"/>`+"\n", px, py, svgCell, svgCell, svgColors[layer.state], layer.opacity)
+					}
+				}
+				continue
+			}
+
+			state := b.cells[idx].state
+
+			if state != cellEmpty && state != cellMiss {
+				fmt.Fprintf(w, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="#fff"/>`+"\n", px, py, svgCell, svgCell, svgColors[state])
+			}
+
+			if state == cellMiss {
+				fmt.Fprintf(w, `<circle cx="%d" cy="%d" r="%d" fill="#336"/>`+"\n", px+svgCell/2, py+svgCell/2, svgCell/6)
+			}
+		}
+	}
+
+	w.WriteString("</g>\n")
+}
+
+func (b *Board) title() string {
+	if b.IsHeatmap() && b.Title == "" {
+		return fmt.Sprintf("1:%d", b.Scale)
+	}
+	if b.IsHeatmap() {
+		return fmt.Sprintf("%s (1:%d)", b.Title, b.Scale)
+	}
+	return b.Title
+}
+
+// Writes the boards side by side as an SVG image.
+func WriteSVG(w io.Writer, boards ...*Board) error {
+	width, height := int64(svgMargin), int64(0)
+	for _, b := range boards {
+		width += b.W*svgCell + svgMargin
+		height = max(height, b.H*svgCell)
+	}
+	height += 2 * svgMargin
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="#fff"/>`+"\n")
+
+	offsetX := int64(svgMargin)
+	for _, b := range boards {
+		b.writeSVG(bw, offsetX)
+		offsetX += b.W*svgCell + svgMargin
+	}
+
+	bw.WriteString("</svg>\n")
+
+	return bw.Flush()
+}
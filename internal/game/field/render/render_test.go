@@ -0,0 +1,95 @@
+package render_test
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/game/field/render"
+)
+
+// . . . .
+// 2 2 . 1
+// . . . .
+func newField(t *testing.T) (field.Configuration, field.Field) {
+	conf := field.Configuration{W: 4, H: 3, Sizes: []int64{1, 1, 0, 0}}
+
+	f := field.NewShipField(0)
+	require.NoError(t, f.Load(conf, slices.Values([]field.Ship{
+		{X: 0, Y: 1, Size: 2},
+		{X: 3, Y: 1, Size: 1},
+	})))
+
+	return conf, f
+}
+
+func renderASCII(t *testing.T, b *render.Board) string {
+	var sb strings.Builder
+	require.NoError(t, b.WriteASCII(&sb))
+	return sb.String()
+}
+
+func TestRender_ASCII(t *testing.T) {
+	conf, f := newField(t)
+
+	assert.Equal(t, ". . . .\n2 2 . 1\n. . . .\n", renderASCII(t, render.New(conf, f, nil)))
+
+	shots := []render.Shot{{X: 0, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 1}, {X: 2, Y: 2}, {X: 7, Y: 7}}
+	assert.Equal(t, ". . . .\nx 2 . X\n. . o .\n", renderASCII(t, render.New(conf, f, shots)))
+}
+
+func TestRender_UnknownField(t *testing.T) {
+	conf, _ := newField(t)
+
+	shots := []render.Shot{{X: 0, Y: 0, Result: field.Miss}, {X: 1, Y: 1, Result: field.Hit}, {X: 3, Y: 2, Result: field.Kill}}
+	assert.Equal(t, "o . . .\n. x . .\n. . . X\n", renderASCII(t, render.New(conf, nil, shots)))
+}
+
+func TestRender_Heatmap(t *testing.T) {
+	dump, err := os.Open("../testdata/field.txt")
+	require.NoError(t, err)
+	defer dump.Close()
+
+	conf := field.Configuration{W: 500, H: 500, Sizes: []int64{14800, 11100, 7400, 3700}}
+	f := field.NewShipField(0)
+	require.NoError(t, f.Load(conf, field.ParseShips(dump)))
+
+	b := render.New(conf, f, nil)
+	require.True(t, b.IsHeatmap())
+	assert.Equal(t, int64(5), b.Scale)
+	assert.Equal(t, int64(100), b.W)
+	assert.Equal(t, int64(100), b.H)
+
+	ascii := renderASCII(t, b)
+	assert.Equal(t, 100, strings.Count(ascii, "\n"))
+	assert.Contains(t, ascii, "@")
+
+	// Fields of any size are fine, as long as they have few ships.
+	huge := field.Configuration{W: 1 << 40, H: 3, Sizes: []int64{1, 0, 0, 0}}
+	f = field.NewShipField(0)
+	require.NoError(t, f.Load(huge, slices.Values([]field.Ship{{X: 1 << 39, Y: 1, Size: 1}})))
+
+	ascii = renderASCII(t, render.New(huge, f, nil))
+	assert.Equal(t, 1, strings.Count(ascii, "\n"))
+	assert.Equal(t, 1, strings.Count(ascii, "@"))
+}
+
+func TestRender_SVG(t *testing.T) {
+	conf, f := newField(t)
+
+	b := render.New(conf, f, []render.Shot{{X: 0, Y: 1}})
+	b.Title = "player"
+
+	var sb strings.Builder
+	require.NoError(t, render.WriteSVG(&sb, b, b))
+
+	svg := sb.String()
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.True(t, strings.HasSuffix(svg, "</svg>\n"))
+	assert.Equal(t, 2, strings.Count(svg, ">player<"))
+}
@@ -1,28 +1,31 @@
 package field
 
 import (
-	"errors"
+	"fmt"
 	"iter"
+	"slices"
 
 	"github.com/dolthub/swiss"
 )
 
-type shipData uint8 // 1:1:2:4 <-> 1:IsVert:Size:Cells
+type shipData uint64 // 1:1:6:56 <-> 1:IsVert:Size:Cells
+
+const deckMask = 1<<MaxShipSize - 1
 
 func newShipData(isVert bool, size int8) shipData {
-	var bits = (uint8(size) - 1) << 4
+	var bits = uint64(size-1) << MaxShipSize
 
-	bits |= (0b1111 << size) & 0b1111
+	bits |= (deckMask << size) & deckMask
 
 	if isVert {
-		return shipData(0b11000000 | bits)
+		return shipData(0b11<<62 | bits)
 	} else {
-		return shipData(0b10000000 | bits)
+		return shipData(0b10<<62 | bits)
 	}
 }
 
 func (c shipData) IsVert() bool {
-	return ((c >> 6) & 0b1) != 0
+	return ((c >> 62) & 0b1) != 0
 }
 
 func (c shipData) IsHor() bool {
@@ -30,7 +33,7 @@ func (c shipData) IsHor() bool {
 }
 
 func (c shipData) Size() int8 {
-	return int8((c>>4)&0b11) + 1
+	return int8((c>>MaxShipSize)&0b111111) + 1
 }
 
 func (c *shipData) MarkHit(idx int8) {
@@ -38,7 +41,7 @@ func (c *shipData) MarkHit(idx int8) {
 }
 
 func (c shipData) IsDead() bool {
-	return (c & 0b1111) == 0b1111
+	return (c & deckMask) == deckMask
 }
 
 type packedPos int64
@@ -53,6 +56,10 @@ type ShipField struct {
 	ships      *swiss.Map[packedPos, shipData]
 	conf       Configuration
 	currConfig Configuration
+
+	// Largest size of ships on the field, i.e. how far
+	// back to look for a ship a cell may belong to.
+	maxSize int8
 }
 
 func NewShipField(sizeHint uint32) *ShipField {
@@ -83,7 +90,7 @@ func (f *ShipField) hitShip(ship shipData, pos packedPos, idx int8) ShootResult
 }
 
 func (f *ShipField) scanIntersectionsLeft(x, y int64) (intersection, bool) {
-	for i := int8(1); i <= int8(min(3, x)); i++ {
+	for i := int8(1); i <= int8(min(int64(f.maxSize-1), x)); i++ {
 		pos := f.makePos(x-int64(i), y)
 		if ship, exists := f.ships.Get(pos); exists && ship.IsHor() {
 			if ship.Size() <= i {
@@ -103,7 +110,7 @@ func (f *ShipField) scanIntersectionsLeft(x, y int64) (intersection, bool) {
 }
 
 func (f *ShipField) scanIntersectionsUp(x, y int64) (intersection, bool) {
-	for i := int8(1); i <= int8(min(3, y)); i++ {
+	for i := int8(1); i <= int8(min(int64(f.maxSize-1), y)); i++ {
 		pos := f.makePos(x, y-int64(i))
 		if ship, exists := f.ships.Get(pos); exists && ship.IsVert() {
 			if ship.Size() <= i {
@@ -190,30 +197,32 @@ func (f *ShipField) checkOuterUpOverlaps(ship Ship) bool {
 
 func (f *ShipField) Load(conf Configuration, ships iter.Seq[Ship]) error {
 	cellCounts := make([]int64, len(conf.Sizes))
-	f.currConfig = conf
 	f.conf = conf
+	f.currConfig = conf
+	f.currConfig.Sizes = slices.Clone(conf.Sizes)
+	f.maxSize = int8(min(conf.MaxSize(), MaxShipSize))
 
 	if conf.W == 0 || conf.H == 0 {
-		return errors.New("invalid field size")
+		return fmt.Errorf("%w: invalid size", ErrInvalidField)
 	}
 
 	for ship := range ships {
-		if ship.Size <= 0 || int(ship.Size) > len(conf.Sizes) {
-			return errors.New("invalid ship size")
+		if ship.Size <= 0 || ship.Size > f.maxSize {
+			return fmt.Errorf("%w: invalid ship size", ErrInvalidField)
 		}
 
 		if ship.IsVert {
 			if ship.X >= conf.W || ship.Y+int64(ship.Size) > conf.H {
-				return errors.New("ship out of bounds")
+				return fmt.Errorf("%w: ship out of bounds", ErrInvalidField)
 			}
 		} else {
 			if ship.Y >= conf.H || ship.X+int64(ship.Size) > conf.W {
-				return errors.New("ship out of bounds")
+				return fmt.Errorf("%w: ship out of bounds", ErrInvalidField)
 			}
 		}
 
 		if f.checkInnerOverlaps(ship) || f.checkOuterLeftOverlaps(ship) || f.checkOuterUpOverlaps(ship) {
-			return errors.New("ships overlap")
+			return fmt.Errorf("%w: ships overlap", ErrInvalidField)
 		}
 
 		pos := f.makePos(ship.X, ship.Y)
@@ -224,7 +233,7 @@ func (f *ShipField) Load(conf Configuration, ships iter.Seq[Ship]) error {
 
 	for i, count := range cellCounts {
 		if count != conf.Sizes[i] {
-			return errors.New("ship count does not match configuration")
+			return fmt.Errorf("%w: ship count does not match configuration", ErrInvalidField)
 		}
 	}
 
@@ -253,7 +262,7 @@ func (f *ShipField) Shoot(x, y int64) ShootResult {
 }
 
 func (f *ShipField) ResetShots() {
-	f.currConfig = f.conf
+	copy(f.currConfig.Sizes, f.conf.Sizes)
 	f.ships.Iter(func(pos packedPos, oldShip shipData) (stop bool) {
 		f.ships.Put(pos, newShipData(oldShip.IsVert(), oldShip.Size()))
 		return
@@ -261,8 +270,18 @@ func (f *ShipField) ResetShots() {
 }
 
 func (f *ShipField) AllDead() bool {
-	return f.currConfig.Sizes[0] == 0 &&
-		f.currConfig.Sizes[1] == 0 &&
-		f.currConfig.Sizes[2] == 0 &&
-		f.currConfig.Sizes[3] == 0
+	return f.currConfig.MaxSize() == 0
+}
+
+func (f *ShipField) Ships() iter.Seq[Ship] {
+	return func(yield func(Ship) bool) {
+		f.ships.Iter(func(pos packedPos, ship shipData) (stop bool) {
+			return !yield(Ship{
+				X:      int64(pos) / f.conf.H,
+				Y:      int64(pos) % f.conf.H,
+				Size:   ship.Size(),
+				IsVert: ship.IsVert(),
+			})
+		})
+	}
 }
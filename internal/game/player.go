@@ -2,6 +2,8 @@ package game
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/mrsobakin/itmournament/internal/game/field"
@@ -31,6 +33,28 @@ func (r Role) String() string {
 	}
 }
 
+func (r Role) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+func (r *Role) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	switch str {
+	case "master":
+		*r = RoleMaster
+	case "slave":
+		*r = RoleSlave
+	default:
+		return fmt.Errorf("invalid role: %q", str)
+	}
+
+	return nil
+}
+
 type TerminationReason int
 
 const (
@@ -88,6 +112,11 @@ type Player interface {
 	// will be returned.
 	RetrieveField(field.Configuration) (field.Field, error)
 
+	// Writes the field to `LoadPath`, so that the player can `load` it.
+	//
+	// Should be called ONLY before the corresponding command is executed.
+	ProvideField(field.Configuration, field.Field) error
+
 	// Terminates player session.
 	Close() error
 }
@@ -96,6 +125,29 @@ type PlayerFactory interface {
 	NewPlayer(context.Context) Player
 }
 
+// Player that couldn't be created, e.g. because its container failed
+// to start. Everything fails with the reason, so that the match still
+// gets a verdict.
+type FailedPlayer struct {
+	Err error
+}
+
+func (p *FailedPlayer) SendCommand(string) (string, error) {
+	return "", p.Err
+}
+
+func (p *FailedPlayer) RetrieveField(field.Configuration) (field.Field, error) {
+	return nil, p.Err
+}
+
+func (p *FailedPlayer) ProvideField(field.Configuration, field.Field) error {
+	return p.Err
+}
+
+func (p *FailedPlayer) Close() error {
+	return nil
+}
+
 type StopwatchPlayer struct {
 	player    Player
 	stopwatch *utils.Stopwatch
@@ -137,10 +189,19 @@ func (p *StopwatchPlayer) SendCommand(command string) (string, error) {
 	return p.player.SendCommand(command)
 }
 
+// Returns total time the player has spent processing commands.
+func (p *StopwatchPlayer) Elapsed() time.Duration {
+	return p.stopwatch.Elapsed()
+}
+
 func (p *StopwatchPlayer) RetrieveField(conf field.Configuration) (field.Field, error) {
 	return p.player.RetrieveField(conf)
 }
 
+func (p *StopwatchPlayer) ProvideField(conf field.Configuration, f field.Field) error {
+	return p.player.ProvideField(conf, f)
+}
+
 func (p *StopwatchPlayer) Close() error {
 	return p.player.Close()
 }
@@ -6,6 +6,15 @@ import (
 	"github.com/mrsobakin/itmournament/internal/game/field"
 )
 
+// Path players are asked to dump their fields to.
+//
+// Players that don't share the filesystem with others may read the
+// field from there directly, while others should redirect the dump.
+const DumpPath string = "/tmp/field.txt"
+
+// Path players are asked to `load` fields provided by the judge from.
+const LoadPath string = "/tmp/fleet.txt"
+
 type PlayerExt struct {
 	Player
 }
@@ -32,7 +41,7 @@ func (p *PlayerExt) SendScanf(cmd string, format string, a ...any) error {
 }
 
 func (p *PlayerExt) RequestAndGetField(conf field.Configuration) (field.Field, error) {
-	resp, err := p.SendCommand("dump /tmp/field.txt")
+	resp, err := p.SendCommand("dump " + DumpPath)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,130 @@
+package judge
+
+import (
+	"fmt"
+	"maps"
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+// Name of the breaker shooter used when none is specified.
+const DefaultBreakerShooter string = "sequential"
+
+// Returned by the judge once it has fired all of its breaker shots.
+// Same as reaching the shot limit, the original master has then
+// failed to show that it can handle its own configuration.
+var errBreakerShotsExhausted error = fmt.Errorf("%w: judge has fired all breaker shots", errShotLimit)
+
+// Maps the number of a shot onto the cell the judge shoots at
+// in the breaker round, returning false once there are no cells left.
+type BreakerShots func(i int64) (x, y int64, ok bool)
+
+// Makes the breaker shots, given the configuration and the field
+// of the original master from the first round.
+type BreakerShooter func(conf field.Configuration, known field.Field) BreakerShots
+
+// Walks all cells row by row.
+func SequentialShooter(conf field.Configuration, _ field.Field) BreakerShots {
+	return func(i int64) (int64, int64, bool) {
+		if i/conf.W >= conf.H {
+			return 0, 0, false
+		}
+		return i % conf.W, i / conf.W, true
+	}
+}
+
+// Walks all cells in random order, without keeping track of
+// them, so that huge fields are fine.
+func RandomShooter(conf field.Configuration, _ field.Field) BreakerShots {
+	hi, n := bits.Mul64(uint64(conf.W), uint64(conf.H))
+
+	// Number of cells doesn't fit, so cells are just sampled.
+	if hi != 0 {
+		return func(int64) (int64, int64, bool) {
+			return rand.Int64N(conf.W), rand.Int64N(conf.H), true
+		}
+	}
+
+	// Affine map `i -> a*i + b (mod n)` is a permutation if `a` is coprime with `n`.
+	a, b := uint64(1), rand.Uint64N(n)
+	for n > 1 {
+		a = rand.Uint64N(n-1) + 1
+		if gcd(a, n) == 1 {
+			break
+		}
+	}
+
+	return func(i int64) (int64, int64, bool) {
+		if uint64(i) >= n {
+			return 0, 0, false
+		}
+
+		hi, lo := bits.Mul64(a, uint64(i))
+		lo, carry := bits.Add64(lo, b, 0)
+		idx := int64(bits.Rem64(hi+carry, lo, n))
+
+		return idx % conf.W, idx / conf.W, true
+	}
+}
+
+// Shoots at cells of ships known from the first round first, sinking
+// them one after another. Unless the judge places the fleets, the
+// original master may have placed its ships elsewhere this time, so
+// then it walks all cells the same as `SequentialShooter`.
+func ShipsShooter(conf field.Configuration, known field.Field) BreakerShots {
+	var cells [][2]int64
+	for _, ship := range sortedShips(known) {
+		for i := range int64(ship.Size) {
+			if ship.IsVert {
+				cells = append(cells, [2]int64{ship.X, ship.Y + i})
+			} else {
+				cells = append(cells, [2]int64{ship.X + i, ship.Y})
+			}
+		}
+	}
+
+	sequential := SequentialShooter(conf, known)
+
+	return func(i int64) (int64, int64, bool) {
+		if i >= int64(len(cells)) {
+			return sequential(i - int64(len(cells)))
+		}
+		return cells[i][0], cells[i][1], true
+	}
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+var breakerShooters = map[string]BreakerShooter{
+	"sequential": SequentialShooter,
+	"random":     RandomShooter,
+	"ships":      ShipsShooter,
+}
+
+// Returns breaker shooter with the given name, or the default one if
+// the name is empty.
+func LookupBreakerShooter(name string) (BreakerShooter, error) {
+	if name == "" {
+		name = DefaultBreakerShooter
+	}
+
+	shooter, ok := breakerShooters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown breaker shooter: %q", name)
+	}
+
+	return shooter, nil
+}
+
+// Returns names of all breaker shooters, sorted.
+func BreakerShooterNames() []string {
+	return slices.Sorted(maps.Keys(breakerShooters))
+}
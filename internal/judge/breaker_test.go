@@ -0,0 +1,124 @@
+package judge_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/bots"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+func collectShots(shots judge.BreakerShots, limit int) [][2]int64 {
+	var cells [][2]int64
+	for i := range int64(limit) {
+		x, y, ok := shots(i)
+		if !ok {
+			break
+		}
+		cells = append(cells, [2]int64{x, y})
+	}
+	return cells
+}
+
+func TestSequentialShooter(t *testing.T) {
+	conf := field.Configuration{W: 3, H: 2}
+
+	cells := collectShots(judge.SequentialShooter(conf, nil), 100)
+	assert.Equal(t, [][2]int64{{0, 0}, {1, 0}, {2, 0}, {0, 1}, {1, 1}, {2, 1}}, cells)
+}
+
+func TestRandomShooter(t *testing.T) {
+	for _, conf := range []field.Configuration{{W: 1, H: 1}, {W: 7, H: 5}, {W: 10, H: 10}, {W: 1, H: 13}} {
+		cells := collectShots(judge.RandomShooter(conf, nil), 1000)
+
+		// Every cell is shot exactly once.
+		require.Len(t, cells, int(conf.W*conf.H))
+		slices.SortFunc(cells, func(a, b [2]int64) int {
+			return int(a[1]*conf.W + a[0] - b[1]*conf.W - b[0])
+		})
+		assert.Equal(t, collectShots(judge.SequentialShooter(conf, nil), 1000), cells)
+	}
+
+	conf := field.Configuration{W: 1 << 62, H: 1 << 62}
+	for _, cell := range collectShots(judge.RandomShooter(conf, nil), 100) {
+		assert.True(t, cell[0] >= 0 && cell[0] < conf.W && cell[1] >= 0 && cell[1] < conf.H)
+	}
+}
+
+func TestShipsShooter(t *testing.T) {
+	conf := field.Configuration{W: 5, H: 5, Sizes: []int64{1, 0, 1, 0}}
+	known := field.NewShipField(0)
+	require.NoError(t, known.Load(conf, slices.Values([]field.Ship{
+		{X: 4, Y: 4, Size: 1},
+		{X: 0, Y: 1, Size: 3, IsVert: true},
+	})))
+
+	cells := collectShots(judge.ShipsShooter(conf, known), 100)
+	require.Len(t, cells, 4+25)
+	assert.Equal(t, [][2]int64{{0, 1}, {0, 2}, {0, 3}, {4, 4}}, cells[:4])
+
+	// Then all cells, in case ships were placed elsewhere.
+	assert.Equal(t, collectShots(judge.SequentialShooter(conf, nil), 100), cells[4:])
+}
+
+// Master placing its fleet anew for the breaker round is still sunk.
+func TestJudge_BreakerShipsShooterReplaced(t *testing.T) {
+	shooter, err := judge.LookupBreakerShooter("ships")
+	require.NoError(t, err)
+
+	j := newJudge()
+	j.BreakerShooter = shooter
+
+	// Bots with no seed place their ships anew every time.
+	master, err := bots.NewPlayerFactory("density", 0)
+	require.NoError(t, err)
+
+	verdict, _ := j.Judge(context.Background(), master, hungryFactory{newBot(t, "random")})
+
+	require.NotNil(t, verdict.Stats.Breaker, verdict.Details)
+	assert.Equal(t, judge.Ok, verdict.Stats.Breaker.Reason, verdict.Details)
+	assert.Equal(t, judge.MasterWon, verdict.Winner)
+}
+
+func TestJudge_BreakerShooters(t *testing.T) {
+	for _, name := range judge.BreakerShooterNames() {
+		t.Run(name, func(t *testing.T) {
+			shooter, err := judge.LookupBreakerShooter(name)
+			require.NoError(t, err)
+
+			j := newJudge()
+			j.BreakerShooter = shooter
+			j.BreakerShots = 5
+
+			verdict, _ := j.Judge(context.Background(), newBot(t, "density"), hungryFactory{newBot(t, "random")})
+
+			// Master can't sink its own fleet while the judge fires 5 shots.
+			assert.Equal(t, judge.Tie, verdict.Winner)
+			assert.Equal(t, judge.ShotLimit, verdict.Stats.Breaker.Reason)
+			assert.LessOrEqual(t, verdict.Stats.Breaker.Master.Shots, int64(5))
+		})
+	}
+
+	_, err := judge.LookupBreakerShooter("nonexistent")
+	assert.Error(t, err)
+}
+
+// Breaker round reaching the shot limit is a tie, whoever's turn it is.
+func TestJudge_BreakerShotLimit(t *testing.T) {
+	for maxShots := int64(1); maxShots <= 6; maxShots++ {
+		j := newJudge()
+		j.MaxShots = maxShots
+
+		verdict, _ := j.Judge(context.Background(), newBot(t, "density"), hungryFactory{newBot(t, "random")})
+
+		require.NotNil(t, verdict.Stats.Breaker, verdict.Details)
+		assert.Equal(t, judge.Tie, verdict.Winner, "max shots %d", maxShots)
+		assert.Equal(t, judge.ShotLimit, verdict.Reason, "max shots %d", maxShots)
+		assert.Equal(t, judge.ShotLimit, verdict.Stats.Breaker.Reason, "max shots %d", maxShots)
+	}
+}
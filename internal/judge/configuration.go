@@ -0,0 +1,35 @@
+package judge
+
+import (
+	"math/rand/v2"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+// Chooses configuration of a match on behalf of the judge.
+type ConfigurationSource func() field.Configuration
+
+// Places ships of the player of the given role on behalf of the judge.
+// Should give the same fleet for the same arguments, so that fleets
+// don't depend on the order matches are played in.
+type PlacementSource func(conf field.Configuration, role game.Role) (field.Field, error)
+
+// Always chooses the same configuration.
+func FixedConfiguration(conf field.Configuration) ConfigurationSource {
+	return func() field.Configuration {
+		return conf
+	}
+}
+
+// Chooses one of the configurations at random, each equally likely.
+// Configuration may be listed several times to make it more likely.
+func SampledConfiguration(confs ...field.Configuration) ConfigurationSource {
+	if len(confs) == 0 {
+		panic("no configurations to sample from")
+	}
+
+	return func() field.Configuration {
+		return confs[rand.IntN(len(confs))]
+	}
+}
@@ -0,0 +1,118 @@
+package judge_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+func TestJudge_SuppliedConfiguration(t *testing.T) {
+	conf := field.Configuration{W: 7, H: 6, Sizes: []int64{2, 1, 1, 0}}
+
+	j := newJudge()
+	j.Configurations = judge.FixedConfiguration(conf)
+
+	verdict, transcript := j.Judge(context.Background(), newBot(t, "density"), newBot(t, "random"))
+	require.Equal(t, judge.Ok, verdict.Reason, verdict.Details)
+	assert.Equal(t, &conf, verdict.Stats.Configuration)
+
+	var masterCommands []string
+	for _, e := range transcript.Rounds[0].Exchanges {
+		if e.Role == game.RoleMaster {
+			masterCommands = append(masterCommands, e.Command)
+		}
+	}
+
+	// Master is told the configuration, same as the slave.
+	assert.Contains(t, masterCommands, "set width 7")
+	assert.Contains(t, masterCommands, "set count 3 1")
+	assert.NotContains(t, masterCommands, "get width")
+}
+
+func TestJudge_InvalidSuppliedConfiguration(t *testing.T) {
+	j := newJudge()
+	j.Configurations = judge.FixedConfiguration(field.Configuration{W: 10, H: 10})
+
+	verdict, _ := j.Judge(context.Background(), newBot(t, "random"), newBot(t, "random"))
+	assert.Equal(t, judge.Tie, verdict.Winner)
+	assert.Equal(t, judge.JudgeError, verdict.Reason)
+}
+
+func TestJudge_InfeasibleSuppliedConfiguration(t *testing.T) {
+	j := newJudge()
+	j.Configurations = judge.FixedConfiguration(field.Configuration{W: 1, H: 1, Sizes: []int64{0, 0, 0, 10}})
+
+	verdict, _ := j.Judge(context.Background(), newBot(t, "random"), newBot(t, "random"))
+	assert.Equal(t, judge.Tie, verdict.Winner)
+	assert.Equal(t, judge.JudgeError, verdict.Reason)
+	assert.Contains(t, verdict.Details, "can't be placed")
+}
+
+func TestJudge_CheckConfiguration(t *testing.T) {
+	j := newJudge()
+
+	assert.NoError(t, j.CheckConfiguration(field.Configuration{W: 10, H: 10, Sizes: []int64{4, 3, 2, 1}}))
+	assert.ErrorContains(t, j.CheckConfiguration(field.Configuration{W: 10, H: 10}), "is invalid")
+	assert.ErrorContains(t, j.CheckConfiguration(field.Configuration{W: 12, H: 12, Sizes: []int64{1, 0, 0, 0, 1}}), "longer than 4")
+	assert.ErrorIs(t, j.CheckConfiguration(field.Configuration{W: 1, H: 1, Sizes: []int64{0, 0, 0, 10}}), field.ErrInfeasible)
+
+	j.MaxShipSize = 5
+	assert.NoError(t, j.CheckConfiguration(field.Configuration{W: 12, H: 12, Sizes: []int64{1, 0, 0, 0, 1}}))
+}
+
+func TestJudge_SuppliedConfigurationNoBreaker(t *testing.T) {
+	j := newJudge()
+	j.Configurations = judge.FixedConfiguration(field.Configuration{W: 10, H: 10, Sizes: []int64{1, 0, 0, 0}})
+
+	verdict, transcript := j.Judge(context.Background(), newBot(t, "random"), hungryFactory{newBot(t, "random")})
+	assert.Equal(t, judge.MasterWon, verdict.Winner)
+	assert.Equal(t, judge.MemoryLimit, verdict.Reason)
+	assert.Nil(t, verdict.Stats.Breaker)
+	assert.Len(t, transcript.Rounds, 1)
+}
+
+func TestSampledConfiguration(t *testing.T) {
+	a := field.Configuration{W: 10, H: 10, Sizes: []int64{4, 3, 2, 1}}
+	b := field.Configuration{W: 5, H: 5, Sizes: []int64{1, 0, 0, 0}}
+
+	sample := judge.SampledConfiguration(a, b)
+
+	seen := make(map[string]bool)
+	for range 100 {
+		seen[sample().String()] = true
+	}
+	assert.Equal(t, map[string]bool{a.String(): true, b.String(): true}, seen)
+
+	assert.Panics(t, func() { judge.SampledConfiguration() })
+}
+
+func TestJudge_MaxShipSize(t *testing.T) {
+	conf := field.Configuration{W: 12, H: 12, Sizes: []int64{2, 1, 0, 0, 1}}
+
+	j := newJudge()
+	j.MaxShipSize = 6
+	j.Configurations = judge.FixedConfiguration(conf)
+
+	verdict, transcript := j.Judge(context.Background(), newBot(t, "density"), newBot(t, "random"))
+	require.Equal(t, judge.Ok, verdict.Reason, verdict.Details)
+	assert.True(t, conf.Equal(*verdict.Stats.Configuration))
+
+	var commands []string
+	for _, e := range transcript.Rounds[0].Exchanges {
+		commands = append(commands, e.Command)
+	}
+	assert.Contains(t, commands, "set count 5 1")
+	assert.Contains(t, commands, "set count 6 0")
+
+	// Ships longer than players are told about can't be chosen.
+	j.MaxShipSize = 4
+	verdict, _ = j.Judge(context.Background(), newBot(t, "density"), newBot(t, "random"))
+	assert.Equal(t, judge.Tie, verdict.Winner)
+	assert.Equal(t, judge.JudgeError, verdict.Reason)
+}
@@ -0,0 +1,72 @@
+package judge
+
+import (
+	"fmt"
+)
+
+// Stable identifier of the way a player has broken the protocol.
+type ErrorCode string
+
+const (
+	// Player replied something other than the expected `ok`.
+	CodeProtocolViolation ErrorCode = "protocol_violation"
+
+	// Player's reply couldn't be parsed, e.g. `get width` returned `ten`.
+	CodeBadResponseFormat ErrorCode = "bad_response_format"
+
+	// Configuration reported by the master is rejected by the rules.
+	CodeInvalidConfiguration ErrorCode = "invalid_configuration"
+
+	// Configuration reported by the master is proven impossible to place,
+	// e.g. ten 4-deck ships on a 1x1 field.
+	CodeInfeasibleConfiguration ErrorCode = "infeasible_configuration"
+
+	// Dumped field doesn't match the configuration, or has ships touching.
+	CodeInvalidField ErrorCode = "invalid_field"
+
+	// Player has shot outside of the field.
+	CodeInvalidShotCoordinates ErrorCode = "invalid_shot_coordinates"
+
+	// Player reported a shot result that doesn't match its own field.
+	CodeWrongShotResult ErrorCode = "wrong_shot_result"
+
+	// Player didn't dump the field, or the dump couldn't be read.
+	CodeFieldDumpMissing ErrorCode = "field_dump_missing"
+
+	// Player's field differs from the one it was asked to `load`.
+	CodeFleetNotLoaded ErrorCode = "fleet_not_loaded"
+)
+
+// Error of a player that is still running, but doesn't follow the
+// protocol, as opposed to the player being terminated.
+type PlayerError struct {
+	Code ErrorCode
+
+	// What has gone wrong, e.g. `failed to start`.
+	Message string
+
+	// Offending command and the player's response to it, if any.
+	Command  string
+	Response string
+
+	// Underlying error, if any.
+	Err error
+}
+
+func (e *PlayerError) Error() string {
+	msg := e.Message
+
+	if e.Command != "" {
+		msg += fmt.Sprintf(": %q returned %q", e.Command, e.Response)
+	}
+
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *PlayerError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,127 @@
+package judge_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+// Bot that misbehaves in a specific way.
+type brokenPlayer struct {
+	game.Player
+
+	// Overrides response to the command, if returns true.
+	respond func(cmd string) (string, bool)
+
+	// Returned by `RetrieveField`, if set.
+	fieldErr error
+}
+
+func (p *brokenPlayer) SendCommand(cmd string) (string, error) {
+	if p.respond != nil {
+		if resp, ok := p.respond(cmd); ok {
+			return resp, nil
+		}
+	}
+	return p.Player.SendCommand(cmd)
+}
+
+func (p *brokenPlayer) RetrieveField(conf field.Configuration) (field.Field, error) {
+	if p.fieldErr != nil {
+		return nil, p.fieldErr
+	}
+	return p.Player.RetrieveField(conf)
+}
+
+type brokenFactory struct {
+	factory  game.PlayerFactory
+	respond  func(cmd string) (string, bool)
+	fieldErr error
+}
+
+func (f *brokenFactory) NewPlayer(ctx context.Context) game.Player {
+	return &brokenPlayer{f.factory.NewPlayer(ctx), f.respond, f.fieldErr}
+}
+
+func replace(command, response string) func(string) (string, bool) {
+	return func(cmd string) (string, bool) {
+		return response, strings.HasPrefix(cmd, command)
+	}
+}
+
+func TestJudge_ErrorCodes(t *testing.T) {
+	cases := []struct {
+		broken   game.Role
+		respond  func(string) (string, bool)
+		fieldErr error
+
+		code     judge.ErrorCode
+		command  string
+		response string
+	}{
+		{game.RoleSlave, replace("create", "nope"), nil, judge.CodeProtocolViolation, "create slave", "nope"},
+		{game.RoleSlave, replace("set width", "done"), nil, judge.CodeProtocolViolation, "set width 10", "done"},
+		{game.RoleSlave, replace("set result", "okay"), nil, judge.CodeProtocolViolation, "set result ", "okay"},
+		{game.RoleMaster, replace("get width", "ten"), nil, judge.CodeBadResponseFormat, "get width", "ten"},
+		{game.RoleMaster, replace("get width", "0"), nil, judge.CodeInvalidConfiguration, "", ""},
+		{game.RoleMaster, replace("get width", "1"), nil, judge.CodeInfeasibleConfiguration, "", ""},
+		{game.RoleMaster, replace("dump", "failed"), nil, judge.CodeFieldDumpMissing, "dump " + game.DumpPath, "failed"},
+		{game.RoleMaster, nil, os.ErrNotExist, judge.CodeFieldDumpMissing, "", ""},
+		{game.RoleMaster, nil, fmt.Errorf("%w: ships overlap", field.ErrInvalidField), judge.CodeInvalidField, "", ""},
+		{game.RoleSlave, replace("shot", "10 0"), nil, judge.CodeInvalidShotCoordinates, "shot", "10 0"},
+		{game.RoleMaster, replace("shot ", "kill"), nil, judge.CodeWrongShotResult, "shot ", "kill"},
+		{game.RoleMaster, replace("shot ", "sunk"), nil, judge.CodeBadResponseFormat, "shot ", "sunk"},
+	}
+
+	for _, c := range cases {
+		broken := &brokenFactory{newBot(t, "random"), c.respond, c.fieldErr}
+
+		master, slave := game.PlayerFactory(broken), newBot(t, "random")
+		if c.broken == game.RoleSlave {
+			master, slave = slave, master
+		}
+
+		verdict, _ := newJudge().Judge(context.Background(), master, slave)
+
+		assert.Equal(t, judge.RuntimeError, verdict.Reason, verdict.Details)
+		assert.Equal(t, judge.ResultFromWinner(c.broken.Other()), verdict.Winner, verdict.Details)
+		assert.Equal(t, c.code, verdict.Code, verdict.Details)
+		assert.True(t, strings.HasPrefix(verdict.Command, c.command), "%q", verdict.Command)
+		assert.Equal(t, c.response, verdict.Response)
+	}
+}
+
+func TestJudge_TerminationHasNoCode(t *testing.T) {
+	broken := &brokenFactory{newBot(t, "random"), nil, game.ErrTerminatedMemoryLimit}
+
+	verdict, _ := newJudge().Judge(context.Background(), broken, newBot(t, "random"))
+
+	assert.Equal(t, judge.MemoryLimit, verdict.Reason)
+	assert.Empty(t, verdict.Code)
+}
+
+type failedFactory struct {
+	err error
+}
+
+func (f failedFactory) NewPlayer(context.Context) game.Player {
+	return &game.FailedPlayer{Err: f.err}
+}
+
+func TestJudge_FailedPlayer(t *testing.T) {
+	failed := failedFactory{errors.New("no such image")}
+
+	verdict, _ := newJudge().Judge(context.Background(), newBot(t, "random"), failed)
+	assert.Equal(t, judge.MasterWon, verdict.Winner)
+	assert.Equal(t, judge.RuntimeError, verdict.Reason)
+	assert.Contains(t, verdict.Details, "no such image")
+}
@@ -0,0 +1,63 @@
+package judge
+
+import (
+	"time"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+type Phase string
+
+const (
+	// Player has been created and assigned its role.
+	PhaseInit Phase = "init"
+
+	// Master has chosen a valid configuration.
+	PhaseConfiguration Phase = "configuration"
+
+	// Player has dumped a valid field.
+	PhaseField Phase = "field"
+
+	// Shot has been made and its result has been confirmed.
+	PhaseShot Phase = "shot"
+
+	// Breaker round is about to start.
+	PhaseBreaker Phase = "breaker"
+
+	// Match is over. Always the last event.
+	PhaseVerdict Phase = "verdict"
+)
+
+type ShotEvent struct {
+	X      int64             `json:"x"`
+	Y      int64             `json:"y"`
+	Result field.ShootResult `json:"result"`
+}
+
+// Progress of a match, as reported to `Judge.Observer`.
+type Event struct {
+	Phase Phase     `json:"phase"`
+	Time  time.Time `json:"time"`
+
+	// Whether the event happened during the breaker round.
+	Breaker bool `json:"breaker"`
+
+	// Player the event is about. For shots, it is the shooter.
+	Role *game.Role `json:"role,omitempty"`
+
+	Configuration *field.Configuration `json:"configuration,omitempty"`
+	Shot          *ShotEvent           `json:"shot,omitempty"`
+	Verdict       *Verdict             `json:"verdict,omitempty"`
+}
+
+type observer func(Event)
+
+func (o observer) emit(e Event) {
+	if o == nil {
+		return
+	}
+
+	e.Time = time.Now()
+	o(e)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/bits"
 	"time"
 
 	"github.com/mrsobakin/itmournament/internal/game"
@@ -15,6 +16,11 @@ var (
 	errTimeoutGlobal = errors.New("global timeout")
 	errTimeoutMaster = errors.New("master timeout")
 	errTimeoutSlave  = errors.New("slave timeout")
+	errShotLimit     = errors.New("shot limit")
+
+	// Wrapped by failures of the judge itself, e.g. a configuration it
+	// has chosen being invalid, which no player is to blame for.
+	errJudge = errors.New("judge error")
 )
 
 type Result int
@@ -42,6 +48,22 @@ func (r Result) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r.String())
 }
 
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	for _, result := range []Result{Tie, MasterWon, SlaveWon} {
+		if result.String() == str {
+			*r = result
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid result: %q", str)
+}
+
 func ResultFromWinner(role game.Role) Result {
 	if role == game.RoleMaster {
 		return MasterWon
@@ -60,6 +82,11 @@ const (
 	MemoryLimit
 	Timeout
 	GlobalTimeout
+	ShotLimit
+
+	// Match couldn't be judged through no fault of the players, so
+	// its verdict is not a result and must not be counted as one.
+	JudgeError
 )
 
 func (r Reason) String() string {
@@ -74,6 +101,10 @@ func (r Reason) String() string {
 		return "TL"
 	case GlobalTimeout:
 		return "GTL"
+	case ShotLimit:
+		return "SL"
+	case JudgeError:
+		return "JE"
 	default:
 		panic("invalid reason")
 	}
@@ -83,45 +114,237 @@ func (r Reason) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r.String())
 }
 
+func (r *Reason) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	for _, reason := range []Reason{Ok, RuntimeError, MemoryLimit, Timeout, GlobalTimeout, ShotLimit, JudgeError} {
+		if reason.String() == str {
+			*r = reason
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid reason: %q", str)
+}
+
 type Verdict struct {
 	Winner  Result `json:"winner"`
 	Reason  Reason `json:"reason"`
 	Details string `json:"details"`
+
+	// Set if a player has broken the protocol, along with the offending
+	// command and response, if there were any. See `PlayerError`.
+	Code     ErrorCode `json:"code,omitempty"`
+	Command  string    `json:"command,omitempty"`
+	Response string    `json:"response,omitempty"`
+
+	// Absent in verdicts made before the stats were collected.
+	Stats *MatchStats `json:"stats,omitempty"`
 }
 
 type Judge struct {
 	PlayerTimeout time.Duration
 	GlobalTimeout time.Duration
+
+	// Rules the match is judged by, `ClassicRules` if not set.
+	Rules Rules
+
+	// Maximum number of shots made by both players in a round,
+	// after which the round is adjudicated by the rules.
+	// If zero, `DefaultMaxShots` of the configuration is used.
+	MaxShots int64
+
+	// Maximum number of exchanges recorded into the transcript of
+	// a round, after which it is truncated.
+	// If zero, `DefaultMaxExchanges` is used.
+	MaxExchanges int
+
+	// Largest ship size players are asked and told about, so that
+	// configurations have counts of sizes from 1 up to it.
+	// If zero, `field.DefaultMaxShipSize` is used.
+	MaxShipSize int
+
+	// If set, the judge chooses configuration of every match and sends
+	// it to both players, instead of asking the master for it.
+	Configurations ConfigurationSource
+
+	// If set, the judge places ships of both players and has them `load`
+	// the fields, instead of letting them place their own. Requires
+	// `Configurations`, so that fleets can be placed beforehand.
+	Placements PlacementSource
+
+	// How the judge shoots in the breaker round, `SequentialShooter` if not set.
+	BreakerShooter BreakerShooter
+
+	// Maximum number of shots the judge fires in the breaker round. Once
+	// they are fired, the round is adjudicated the same as if it reached
+	// `MaxShots`. If zero, only `MaxShots` applies.
+	BreakerShots int64
+
+	// If set, is called on every phase transition of a match. It is
+	// called synchronously, so it must not block for long.
+	Observer func(Event)
+}
+
+// Time the judge spends on proving that ships of a configuration can't
+// be placed. If it can't tell in time, the master's dump will.
+const feasibilityBudget = 100 * time.Millisecond
+
+// Default shot limit is capped, so that rounds on huge fields
+// are adjudicated before they run into the global timeout.
+const maxDefaultShots int64 = 1_000_000
+
+// Exchanges recorded per round if `Judge.MaxExchanges` is not set, so
+// that transcripts of long rounds on huge fields stay within memory.
+const DefaultMaxExchanges = 100_000
+
+// Returns the shot limit used if `Judge.MaxShots` is not set. It is
+// enough for both players to shoot every cell of the opponent's field.
+func DefaultMaxShots(conf field.Configuration) int64 {
+	hi, cells := bits.Mul64(uint64(conf.W), uint64(conf.H))
+	if hi != 0 || cells > uint64(maxDefaultShots/2) {
+		return maxDefaultShots
+	}
+	return 2 * int64(cells)
+}
+
+// Maps error that has ended the round onto the verdict reason.
+func classifyError(err error) Reason {
+	switch {
+	case err == nil, errors.Is(err, errPlayerWon):
+		return Ok
+	case errors.Is(err, errJudge):
+		return JudgeError
+	case errors.Is(err, errTimeoutGlobal):
+		return GlobalTimeout
+	case errors.Is(err, errShotLimit):
+		return ShotLimit
+	case errors.Is(err, errTimeoutMaster), errors.Is(err, errTimeoutSlave):
+		return Timeout
+	case errors.Is(err, game.ErrTerminatedMemoryLimit):
+		return MemoryLimit
+	default:
+		return RuntimeError
+	}
+}
+
+// Returns whether the error is due to the player being terminated
+// or running out of time, rather than due to the player misbehaving.
+func isTermination(err error) bool {
+	var terminated *game.ErrorTerminated
+	return errors.As(err, &terminated) || classifyError(err) != RuntimeError
+}
+
+func outcomeOf(result *roleError) Outcome {
+	if errors.Is(result.Err, errPlayerWon) {
+		return Outcome{Role: result.Role}
+	}
+
+	return Outcome{
+		Role:   result.Role,
+		Err:    result.Err,
+		Reason: classifyError(result.Err),
+	}
+}
+
+func (j *Judge) maxShipSize() int {
+	if j.MaxShipSize <= 0 {
+		return field.DefaultMaxShipSize
+	}
+	return j.MaxShipSize
 }
 
-// As per our rules:
-//   - If player wins, he wins.
-//   - If player errors out, the other player wins.
-//   - If slave loses due to ML, breaker round is
-//     conducted to determine whether the master
-//     is able to handle its own configuration.
-//   - If he can't, it's a tie.
-func (j *Judge) judgeMatch(ctx context.Context, masterFactory, slaveFactory game.PlayerFactory) (Result, error) {
+func (j *Judge) maxExchanges() int {
+	if j.MaxExchanges <= 0 {
+		return DefaultMaxExchanges
+	}
+	return j.MaxExchanges
+}
+
+func (j *Judge) rules() Rules {
+	if j.Rules == nil {
+		return ClassicRules{}
+	}
+	return j.Rules
+}
+
+// Fails if the judge can't choose the configuration for players, as it
+// is invalid by the rules, has ships longer than `MaxShipSize` or can't
+// be placed. Meant to be checked once before any match is judged.
+func (j *Judge) CheckConfiguration(conf field.Configuration) error {
+	if err := j.rules().ValidateConfiguration(conf); err != nil {
+		return fmt.Errorf("configuration %s is invalid: %w", conf, err)
+	}
+	if conf.MaxSize() > j.maxShipSize() {
+		return fmt.Errorf("configuration %s has ships longer than %d", conf, j.maxShipSize())
+	}
+	if feasibility, err := field.CheckFeasibility(conf, feasibilityBudget); feasibility == field.Infeasible {
+		return fmt.Errorf("configuration %s can't be placed: %w", conf, err)
+	}
+	return nil
+}
+
+// Plays the match, leaving its adjudication to the rules.
+func (j *Judge) judgeMatch(ctx context.Context, masterFactory, slaveFactory game.PlayerFactory, transcript *Transcript, stats *MatchStats) (Result, error) {
+	rules := j.rules()
+
 	var masterField field.Field
 	var conf field.Configuration
 
+	var supplied *field.Configuration
+	if j.Configurations != nil {
+		chosen := j.Configurations()
+		if err := j.CheckConfiguration(chosen); err != nil {
+			return Tie, fmt.Errorf("%w: judge has chosen bad configuration: %w", errJudge, err)
+		}
+		supplied = &chosen
+	}
+
+	var masterFleet, slaveFleet field.Field
+	if j.Placements != nil {
+		if supplied == nil {
+			return Tie, fmt.Errorf("%w: judge can't place ships without choosing configuration", errJudge)
+		}
+
+		var err error
+		if masterFleet, err = j.Placements(*supplied, game.RoleMaster); err == nil {
+			slaveFleet, err = j.Placements(*supplied, game.RoleSlave)
+		}
+		if err != nil {
+			return Tie, fmt.Errorf("%w: judge has failed to place ships: %w", errJudge, err)
+		}
+	}
+
 	{
 		master := masterFactory.NewPlayer(ctx)
 		slave := slaveFactory.NewPlayer(ctx)
 
-		round := newRound(master, slave)
+		round := newRound(master, slave, rules, j.MaxShots, transcript.newRound(false, j.maxExchanges()), j.Observer)
+		round.supplied = supplied
+		round.maxShipSize = j.maxShipSize()
+		round.masterFleet, round.slaveFleet = masterFleet, slaveFleet
 
-		result := round.Judge()
-		if errors.Is(result.Err, errPlayerWon) {
-			return ResultFromWinner(result.Role), nil
-		}
+		outcome := outcomeOf(round.Judge())
+		outcome.JudgeConfiguration = supplied != nil
+
+		// Players are started anew for the breaker round, so these
+		// are of no use anymore.
+		master.Close()
+		slave.Close()
+		stats.RoundStats = round.Stats()
 
-		if !errors.Is(result.Err, game.ErrTerminatedMemoryLimit) {
-			return ResultFromWinner(result.Role.Other()), result.Err
+		if outcome.Reason == ShotLimit {
+			result := rules.AdjudicateShotLimit(stats.Master.Destroyed, stats.Slave.Destroyed)
+			return result, fmt.Errorf("%w, master destroyed %d cells, slave destroyed %d",
+				outcome.Err, stats.Master.Destroyed, stats.Slave.Destroyed)
 		}
 
-		if result.Role == game.RoleMaster {
-			return SlaveWon, result.Err
+		result, breaker := rules.Adjudicate(outcome)
+		if !breaker {
+			return result, outcome.Err
 		}
 
 		masterField = round.masterField
@@ -129,70 +352,81 @@ func (j *Judge) judgeMatch(ctx context.Context, masterFactory, slaveFactory game
 		conf = round.conf
 	}
 
-	mockMaster := newMockMaster(masterField, conf)
+	observer(j.Observer).emit(Event{Phase: PhaseBreaker, Breaker: true})
+
+	shooter := j.BreakerShooter
+	if shooter == nil {
+		shooter = SequentialShooter
+	}
+
+	mockMaster := newMockMaster(masterField, conf, shooter(conf, masterField), j.BreakerShots)
 
 	master := masterFactory.NewPlayer(ctx)
-	round := newRound(mockMaster, master)
-	result := round.Judge()
+	round := newRound(mockMaster, master, rules, j.MaxShots, transcript.newRound(true, j.maxExchanges()), j.Observer)
+
+	// Original master defends the same fleet it had.
+	round.slaveFleet = masterFleet
+	round.maxShipSize = j.maxShipSize()
 
-	// If no errors on master side or master lost.
-	if result.Role != game.RoleSlave {
-		return ResultFromWinner(game.RoleMaster), nil
+	outcome := outcomeOf(round.Judge())
+	master.Close()
+
+	// Limit, either of the round or of the breaker shots, is reached on
+	// someone's turn, but whoever it is, it is the original master that
+	// hasn't managed to win in time.
+	if outcome.Reason == ShotLimit {
+		outcome.Role = game.RoleSlave
 	}
 
-	// If master won.
-	if errors.Is(result.Err, errPlayerWon) {
-		return ResultFromWinner(game.RoleMaster), nil
+	result := rules.AdjudicateBreaker(outcome)
+
+	stats.Breaker = &BreakerStats{
+		RoundStats: round.Stats(),
+		Result:     result,
+		Reason:     outcome.Reason,
 	}
 
-	// If master had ANY error.
-	err := fmt.Errorf("error during breaker round: %w", result.Err)
-	return Tie, err
+	// Errors of the judge itself are not the players' business.
+	if outcome.Won() || outcome.Role != game.RoleSlave {
+		return result, nil
+	}
+
+	return result, fmt.Errorf("error during breaker round: %w", outcome.Err)
 }
 
-func (j *Judge) Judge(ctx context.Context, master, slave game.PlayerFactory) Verdict {
+// Judges a match between two players, returning the verdict and
+// the transcript of everything that happened during the match.
+func (j *Judge) Judge(ctx context.Context, master, slave game.PlayerFactory) (Verdict, *Transcript) {
 	swMaster := game.NewStopwatchPlayerFactory(master, j.PlayerTimeout, errTimeoutMaster)
 	swSlave := game.NewStopwatchPlayerFactory(slave, j.PlayerTimeout, errTimeoutSlave)
 
 	limitedCtx, cancel := context.WithTimeoutCause(ctx, j.GlobalTimeout, errTimeoutGlobal)
 	defer cancel()
 
-	verdict, details := j.judgeMatch(limitedCtx, swMaster, swSlave)
-
-	reason := func() Reason {
-		if errors.Is(details, errTimeoutGlobal) {
-			return GlobalTimeout
-		}
-
-		if errors.Is(details, errTimeoutMaster) {
-			verdict = SlaveWon
-			return Timeout
-		}
-
-		if errors.Is(details, errTimeoutSlave) {
-			verdict = MasterWon
-			return Timeout
-		}
-
-		if errors.Is(details, game.ErrTerminatedMemoryLimit) {
-			return MemoryLimit
-		}
-
-		if details != nil {
-			return RuntimeError
-		}
-
-		return Ok
-	}()
+	transcript := &Transcript{}
+	stats := &MatchStats{}
+	result, details := j.judgeMatch(limitedCtx, swMaster, swSlave, transcript, stats)
 
 	detailsStr := ""
 	if details != nil {
 		detailsStr = details.Error()
 	}
 
-	return Verdict{
-		Winner:  verdict,
-		Reason:  reason,
+	v := Verdict{
+		Winner:  result,
+		Reason:  classifyError(details),
 		Details: detailsStr,
+		Stats:   stats,
+	}
+
+	var playerErr *PlayerError
+	if errors.As(details, &playerErr) {
+		v.Code = playerErr.Code
+		v.Command = playerErr.Command
+		v.Response = playerErr.Response
 	}
+
+	observer(j.Observer).emit(Event{Phase: PhaseVerdict, Verdict: &v})
+
+	return v, transcript
 }
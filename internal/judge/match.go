@@ -1,8 +1,10 @@
 package judge
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/mrsobakin/itmournament/internal/game"
 	"github.com/mrsobakin/itmournament/internal/game/field"
@@ -35,19 +37,51 @@ type round struct {
 	master, slave           game.PlayerExt
 	masterField, slaveField field.Field
 	conf                    field.Configuration
+	transcript              *RoundTranscript
+	observer                observer
+	rules                   Rules
+	maxShots                int64
+	stats                   RoundStats
+
+	// Ships of sizes up to this one are asked and told about.
+	maxShipSize int
+
+	// Configuration chosen by the judge, if the master doesn't choose it.
+	supplied *field.Configuration
+
+	// Fields provided by the judge, if players don't place their own ships.
+	masterFleet, slaveFleet field.Field
+
+	// Ship cells destroyed so far, so that cells shot again aren't counted twice.
+	destroyed map[destroyedCell]struct{}
 }
 
-func newRound(master, slave game.Player) *round {
+type destroyedCell struct {
+	shooter game.Role
+	x, y    int64
+}
+
+func newRound(master, slave game.Player, rules Rules, maxShots int64, transcript *RoundTranscript, observer observer) *round {
 	return &round{
 		master: game.PlayerExt{
-			Player: master,
+			Player: newRecordingPlayer(master, game.RoleMaster, transcript),
 		},
 		slave: game.PlayerExt{
-			Player: slave,
+			Player: newRecordingPlayer(slave, game.RoleSlave, transcript),
 		},
+		transcript: transcript,
+		observer:   observer,
+		rules:      rules,
+		maxShots:   maxShots,
+		destroyed:  make(map[destroyedCell]struct{}),
 	}
 }
 
+func (r *round) emit(e Event) {
+	e.Breaker = r.transcript.Breaker
+	r.observer.emit(e)
+}
+
 func (r *round) playerByRole(role game.Role) game.PlayerExt {
 	if role == game.RoleMaster {
 		return r.master
@@ -64,25 +98,97 @@ func (r *round) fieldByRole(role game.Role) field.Field {
 	}
 }
 
-func (r *round) InitPlayer(role game.Role) *roleError {
-	player := r.playerByRole(role)
+func (r *round) fleetByRole(role game.Role) field.Field {
+	if role == game.RoleMaster {
+		return r.masterFleet
+	} else {
+		return r.slaveFleet
+	}
+}
+
+func (r *round) playerStats(role game.Role) *PlayerStats {
+	if role == game.RoleMaster {
+		return &r.stats.Master
+	} else {
+		return &r.stats.Slave
+	}
+}
+
+func (r *round) recordShot(shooterRole game.Role, x, y int64, result field.ShootResult) {
+	stats := r.playerStats(shooterRole)
+	stats.addShot(result)
+
+	if result == field.Miss {
+		return
+	}
+
+	cell := destroyedCell{shooterRole, x, y}
+	if _, ok := r.destroyed[cell]; !ok {
+		r.destroyed[cell] = struct{}{}
+		stats.Destroyed++
+	}
+}
+
+// Returns stats of the round so far, with time used by the players.
+func (r *round) Stats() RoundStats {
+	stats := r.stats
+	stats.Master.Time = r.master.Player.(*recordingPlayer).elapsed()
+	stats.Slave.Time = r.slave.Player.(*recordingPlayer).elapsed()
+	return stats
+}
 
-	resp, err := player.SendCommand("create " + role.String())
+// Sends the command, failing with the given code if the response is not `ok`.
+func (r *round) expectOk(role game.Role, cmd string, code ErrorCode, msg string) *roleError {
+	resp, err := r.playerByRole(role).SendCommand(cmd)
 	if err != nil {
-		return failedAs(role, fmt.Errorf("failed to create role: %w", err))
+		return failedAs(role, fmt.Errorf("%s: %w", msg, err))
 	}
 	if resp != "ok" {
-		return failedAs(role, fmt.Errorf("failed to create role, returned: %q", resp))
+		return failedAs(role, &PlayerError{
+			Code:     code,
+			Message:  msg,
+			Command:  cmd,
+			Response: resp,
+		})
 	}
+	return nil
+}
 
-	resp, err = player.SendCommand("set strategy custom")
+// Sends the command and parses the response, failing if it doesn't match the format.
+func (r *round) scanf(role game.Role, cmd string, msg string, format string, a ...any) (string, *roleError) {
+	resp, err := r.playerByRole(role).SendCommand(cmd)
 	if err != nil {
-		return failedAs(role, fmt.Errorf("failed to set strategy: %w", err))
+		return "", failedAs(role, fmt.Errorf("%s: %w", msg, err))
 	}
-	if resp != "ok" {
-		return failedAs(role, fmt.Errorf("failed to set strategy, returned: %q", resp))
+
+	n, err := fmt.Sscanf(resp, format, a...)
+	if err == nil && n != len(a) {
+		err = errors.New("response does not match format")
+	}
+	if err != nil {
+		return "", failedAs(role, &PlayerError{
+			Code:     CodeBadResponseFormat,
+			Message:  msg,
+			Command:  cmd,
+			Response: resp,
+			Err:      err,
+		})
+	}
+
+	return resp, nil
+}
+
+func (r *round) InitPlayer(role game.Role) *roleError {
+	if err := r.expectOk(role, "create "+role.String(), CodeProtocolViolation, "failed to create role"); err != nil {
+		return err
+	}
+
+	if err := r.expectOk(role, "set strategy custom", CodeProtocolViolation, "failed to set strategy"); err != nil {
+		return err
 	}
 
+	r.emit(Event{Phase: PhaseInit, Role: &role})
+
 	return nil
 }
 
@@ -90,119 +196,233 @@ func (r *round) confParams() []struct {
 	name string
 	val  *int64
 } {
-	return []struct {
+	params := []struct {
 		name string
 		val  *int64
 	}{
 		{"width", &r.conf.W},
 		{"height", &r.conf.H},
-		{"count 1", &r.conf.Sizes[0]},
-		{"count 2", &r.conf.Sizes[1]},
-		{"count 3", &r.conf.Sizes[2]},
-		{"count 4", &r.conf.Sizes[3]},
 	}
+
+	for i := range r.conf.Sizes {
+		params = append(params, struct {
+			name string
+			val  *int64
+		}{fmt.Sprintf("count %d", i+1), &r.conf.Sizes[i]})
+	}
+
+	return params
 }
 
 func (r *round) RequestConfiguration() *roleError {
+	r.conf = field.Configuration{Sizes: make([]int64, r.maxShipSize)}
+
 	for _, arg := range r.confParams() {
-		if err := r.master.SendScanf("get "+arg.name, "%d", arg.val); err != nil {
-			return failedAs(game.RoleMaster, fmt.Errorf("failed to get configuration: %w", err))
+		if _, err := r.scanf(game.RoleMaster, "get "+arg.name, "failed to get configuration", "%d", arg.val); err != nil {
+			return err
 		}
 	}
 
-	if err := r.conf.IsValid(); err != nil {
-		return failedAs(game.RoleMaster, fmt.Errorf("invalid configuration: %w", err))
+	r.recordConfiguration()
+
+	if err := r.rules.ValidateConfiguration(r.conf); err != nil {
+		return failedAs(game.RoleMaster, &PlayerError{
+			Code:    CodeInvalidConfiguration,
+			Message: "invalid configuration",
+			Err:     err,
+		})
+	}
+
+	if feasibility, err := field.CheckFeasibility(r.conf, feasibilityBudget); feasibility == field.Infeasible {
+		return failedAs(game.RoleMaster, &PlayerError{
+			Code:    CodeInfeasibleConfiguration,
+			Message: "infeasible configuration",
+			Err:     err,
+		})
 	}
 
+	r.emit(Event{Phase: PhaseConfiguration, Configuration: r.stats.Configuration})
+
 	return nil
 }
 
-func (r *round) TransferConfiguration() *roleError {
+func (r *round) recordConfiguration() {
+	conf := r.conf
+	r.transcript.Configuration = &conf
+	r.stats.Configuration = &conf
+}
+
+// Sends configuration chosen by the judge to the master, which
+// then is on equal footing with the slave.
+func (r *round) SupplyConfiguration() *roleError {
+	r.conf = r.supplied.Resized(r.maxShipSize)
+	r.recordConfiguration()
+
+	r.emit(Event{Phase: PhaseConfiguration, Configuration: r.stats.Configuration})
+
+	return r.TransferConfiguration(game.RoleMaster)
+}
+
+func (r *round) TransferConfiguration(role game.Role) *roleError {
 	for _, arg := range r.confParams() {
-		resp, err := r.slave.Sendf("set %s %d", arg.name, *arg.val)
-		if err != nil {
-			return failedAs(game.RoleSlave, fmt.Errorf("failed to set configuration: %w", err))
-		}
-		if resp != "ok" {
-			return failedAs(game.RoleSlave, fmt.Errorf("failed to set configuration, returned: %q", resp))
+		cmd := fmt.Sprintf("set %s %d", arg.name, *arg.val)
+		if err := r.expectOk(role, cmd, CodeProtocolViolation, "failed to set configuration"); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// Asks the player to dump its field and reads it.
+func (r *round) requestField(role game.Role) (field.Field, *roleError) {
+	if err := r.expectOk(role, "dump "+game.DumpPath, CodeFieldDumpMissing, "failed to dump field"); err != nil {
+		return nil, err
+	}
+
+	f, err := r.playerByRole(role).RetrieveField(r.conf)
+	if err == nil {
+		return f, nil
+	}
+
+	code := CodeFieldDumpMissing
+	switch {
+	case errors.Is(err, field.ErrInvalidField):
+		code = CodeInvalidField
+	case isTermination(err):
+		return nil, failedAs(role, fmt.Errorf("failed to get field: %w", err))
+	}
+
+	return nil, failedAs(role, &PlayerError{
+		Code:    code,
+		Message: "failed to get field",
+		Err:     err,
+	})
+}
+
+// Provides the field to the player and asks it to load it.
+func (r *round) LoadFleet(role game.Role, fleet field.Field) *roleError {
+	if err := r.playerByRole(role).ProvideField(r.conf, fleet); err != nil {
+		return failedAs(role, fmt.Errorf("failed to provide field: %w", err))
+	}
+
+	return r.expectOk(role, "load "+game.LoadPath, CodeProtocolViolation, "failed to load field")
+}
+
 func (r *round) StartPlayer(role game.Role) *roleError {
-	player := r.playerByRole(role)
+	fleet := r.fleetByRole(role)
+	if fleet != nil {
+		if err := r.LoadFleet(role, fleet); err != nil {
+			return err
+		}
+	}
+
+	if err := r.expectOk(role, "start", CodeProtocolViolation, "failed to start"); err != nil {
+		return err
+	}
 
-	resp, err := player.SendCommand("start")
+	f, err := r.requestField(role)
 	if err != nil {
-		return failedAs(role, fmt.Errorf("failed to start: %w", err))
+		return err
 	}
-	if resp != "ok" {
-		return failedAs(role, fmt.Errorf("failed to start, returned: %q", resp))
+
+	if fleet != nil && !sameShips(f, fleet) {
+		return failedAs(role, &PlayerError{
+			Code:    CodeFleetNotLoaded,
+			Message: "dumped field differs from the loaded one",
+		})
 	}
 
 	if role == game.RoleMaster {
-		r.masterField, err = player.RequestAndGetField(r.conf)
+		r.masterField = f
 	} else {
-		r.slaveField, err = player.RequestAndGetField(r.conf)
-	}
-	if err != nil {
-		return failedAs(role, fmt.Errorf("failed to get field: %w", err))
+		r.slaveField = f
 	}
 
+	r.emit(Event{Phase: PhaseField, Role: &role})
+
 	return nil
 }
 
+func sortedShips(f field.Field) []field.Ship {
+	return slices.SortedFunc(f.Ships(), func(a, b field.Ship) int {
+		return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+	})
+}
+
+func sameShips(a, b field.Field) bool {
+	return slices.Equal(sortedShips(a), sortedShips(b))
+}
+
 func (r *round) isValidShot(x, y int64) bool {
 	return x >= 0 && y >= 0 && x < r.conf.W && y < r.conf.H
 }
 
+// Makes a single shot, returning whether the shooter keeps the turn.
 func (r *round) Shoot(shooterRole game.Role) (bool, *roleError) {
 	victimRole := shooterRole.Other()
 
-	shooter := r.playerByRole(shooterRole)
 	victim := r.playerByRole(victimRole)
 
 	victimField := r.fieldByRole(victimRole)
 
 	var x, y int64
-	if err := shooter.SendScanf("shot", "%d %d", &x, &y); err != nil {
-		return false, failedAs(shooterRole, fmt.Errorf("failed to request shoot coordinaes: %w", err))
+	coords, rerr := r.scanf(shooterRole, "shot", "failed to request shoot coordinates", "%d %d", &x, &y)
+	if rerr != nil {
+		return false, rerr
 	}
 
 	if !r.isValidShot(x, y) {
-		return false, failedAs(shooterRole, fmt.Errorf("invalid shoot position %d %d", x, y))
+		return false, failedAs(shooterRole, &PlayerError{
+			Code:     CodeInvalidShotCoordinates,
+			Message:  "invalid shoot position",
+			Command:  "shot",
+			Response: coords,
+		})
 	}
 
-	resp, err := victim.Sendf("shot %d %d", x, y)
+	cmd := fmt.Sprintf("shot %d %d", x, y)
+	resp, err := victim.SendCommand(cmd)
 	if err != nil {
 		return false, failedAs(victimRole, fmt.Errorf("failed to shoot: %w", err))
 	}
 
 	var result field.ShootResult
 	if err := result.FromString(resp); err != nil {
-		return false, failedAs(victimRole, err)
+		return false, failedAs(victimRole, &PlayerError{
+			Code:     CodeBadResponseFormat,
+			Message:  "failed to shoot",
+			Command:  cmd,
+			Response: resp,
+			Err:      err,
+		})
 	}
 
 	expectedResult := victimField.Shoot(x, y)
 
 	if result != expectedResult {
-		return false, failedAs(victimRole, fmt.Errorf("victim returned invalid shoot result: %d", result))
+		return false, failedAs(victimRole, &PlayerError{
+			Code:     CodeWrongShotResult,
+			Message:  fmt.Sprintf("victim returned invalid shoot result, expected %s", expectedResult),
+			Command:  cmd,
+			Response: resp,
+		})
 	}
 
-	resp, err = shooter.Sendf("set result %s", resp)
-	if err != nil {
-		return false, failedAs(shooterRole, fmt.Errorf("failed to set shoot result: %w", err))
-	}
-	if resp != "ok" {
-		return false, failedAs(shooterRole, fmt.Errorf("failed to set shoot result, returned: %q", resp))
+	r.recordShot(shooterRole, x, y, result)
+
+	r.emit(Event{
+		Phase: PhaseShot,
+		Role:  &shooterRole,
+		Shot:  &ShotEvent{x, y, result},
+	})
+
+	if err := r.expectOk(shooterRole, "set result "+resp, CodeProtocolViolation, "failed to set shoot result"); err != nil {
+		return false, err
 	}
 
-	var hit bool
-	if !victimField.AllDead() {
-		hit = (result != field.Miss)
-		return hit, nil
+	if !r.rules.Lost(victimField) {
+		return r.rules.KeepsTurn(result), nil
 	}
 
 	return true, wonAs(shooterRole)
@@ -217,8 +437,14 @@ func (r *round) Judge() *roleError {
 		return err
 	}
 
-	if err := r.RequestConfiguration(); err != nil {
-		return err
+	if r.supplied != nil {
+		if err := r.SupplyConfiguration(); err != nil {
+			return err
+		}
+	} else {
+		if err := r.RequestConfiguration(); err != nil {
+			return err
+		}
 	}
 
 	if err := r.StartPlayer(game.RoleMaster); err != nil {
@@ -229,7 +455,7 @@ func (r *round) Judge() *roleError {
 	// master field is dumped and checked.
 	// This prevents master from asking slave for fields it
 	// itself is unable to generate.
-	if err := r.TransferConfiguration(); err != nil {
+	if err := r.TransferConfiguration(game.RoleSlave); err != nil {
 		return err
 	}
 
@@ -237,15 +463,26 @@ func (r *round) Judge() *roleError {
 		return err
 	}
 
-	currentPlayer := game.RoleSlave
-	for {
-		hit, err := r.Shoot(currentPlayer)
+	maxShots := r.maxShots
+	if maxShots <= 0 {
+		maxShots = DefaultMaxShots(r.conf)
+	}
+
+	currentPlayer := r.rules.FirstShooter()
+	r.stats.Turns++
+	for shots := int64(0); ; shots++ {
+		if shots >= maxShots {
+			return failedAs(currentPlayer, fmt.Errorf("%w of %d shots reached", errShotLimit, maxShots))
+		}
+
+		keepsTurn, err := r.Shoot(currentPlayer)
 		if err != nil {
 			return err
 		}
 
-		if !hit {
+		if !keepsTurn {
 			currentPlayer = currentPlayer.Other()
+			r.stats.Turns++
 		}
 	}
 }
@@ -7,33 +7,49 @@ import (
 	"github.com/mrsobakin/itmournament/internal/game/field"
 )
 
+// Master played by the judge in the breaker round. It owns the field
+// of the original master, and shoots according to the breaker shots,
+// ignoring their results.
 type mockPlayer struct {
 	conf  field.Configuration
 	field field.Field
 
-	x, y int64
+	shots    BreakerShots
+	fired    int64
+	maxShots int64
 }
 
-func newMockMaster(field field.Field, conf field.Configuration) *mockPlayer {
+func newMockMaster(field field.Field, conf field.Configuration, shots BreakerShots, maxShots int64) *mockPlayer {
 	return &mockPlayer{
-		conf:  conf,
-		field: field,
-		x:     0,
-		y:     0,
+		conf:     conf,
+		field:    field,
+		shots:    shots,
+		maxShots: maxShots,
+	}
+}
+
+func (p *mockPlayer) shoot() (string, error) {
+	if p.maxShots > 0 && p.fired >= p.maxShots {
+		return "", errBreakerShotsExhausted
 	}
 
+	x, y, ok := p.shots(p.fired)
+	if !ok {
+		return "", errBreakerShotsExhausted
+	}
+
+	p.fired++
+
+	return fmt.Sprintf("%d %d", x, y), nil
 }
 
 func (p *mockPlayer) SendCommand(cmd string) (string, error) {
+	var size int
+	if n, _ := fmt.Sscanf(cmd, "get count %d", &size); n == 1 {
+		return fmt.Sprint(p.conf.Count(size)), nil
+	}
+
 	switch cmd {
-	case "get count 1":
-		return fmt.Sprint(p.conf.Sizes[0]), nil
-	case "get count 2":
-		return fmt.Sprint(p.conf.Sizes[1]), nil
-	case "get count 3":
-		return fmt.Sprint(p.conf.Sizes[2]), nil
-	case "get count 4":
-		return fmt.Sprint(p.conf.Sizes[3]), nil
 	case "get width":
 		return fmt.Sprint(p.conf.W), nil
 	case "get height":
@@ -41,7 +57,7 @@ func (p *mockPlayer) SendCommand(cmd string) (string, error) {
 	case "win":
 		return "no", nil
 	case "shot":
-		return fmt.Sprintf("%d %d", p.x, p.y), nil
+		return p.shoot()
 	}
 
 	if strings.HasPrefix(cmd, "shot ") {
@@ -52,22 +68,6 @@ func (p *mockPlayer) SendCommand(cmd string) (string, error) {
 		return result.String(), nil
 	}
 
-	switch cmd {
-	case "set result miss":
-		// Good. We found an empty cell.
-	case "set result hit", "Set result kill":
-		p.x += 1
-
-		if p.x == p.conf.W {
-			p.x = 0
-			p.y += 1
-		}
-
-		if p.y == p.conf.H {
-			p.y = 0
-		}
-	}
-
 	return "ok", nil
 }
 
@@ -75,6 +75,10 @@ func (p *mockPlayer) RetrieveField(field.Configuration) (field.Field, error) {
 	return p.field, nil
 }
 
+func (p *mockPlayer) ProvideField(field.Configuration, field.Field) error {
+	return nil
+}
+
 func (p *mockPlayer) Close() error {
 	return nil
 }
@@ -0,0 +1,88 @@
+package judge_test
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/bots"
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+var placementConfiguration = field.Configuration{W: 8, H: 8, Sizes: []int64{3, 2, 1, 1}}
+
+// Places fleets same as bots do, remembering them.
+func recordedPlacements(seed uint64, fleets *[][]field.Ship) judge.PlacementSource {
+	place := bots.Placements(seed)
+
+	return func(conf field.Configuration, role game.Role) (field.Field, error) {
+		f, err := place(conf, role)
+		if err == nil {
+			*fleets = append(*fleets, slices.SortedFunc(f.Ships(), func(a, b field.Ship) int {
+				return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+			}))
+		}
+		return f, err
+	}
+}
+
+func TestJudge_FixedPlacement(t *testing.T) {
+	var fleets [][]field.Ship
+
+	j := newJudge()
+	j.Configurations = judge.FixedConfiguration(placementConfiguration)
+	j.Placements = recordedPlacements(1, &fleets)
+
+	verdict, transcript := j.Judge(context.Background(), newBot(t, "density"), newBot(t, "random"))
+	require.Equal(t, judge.Ok, verdict.Reason, verdict.Details)
+	require.Len(t, fleets, 2)
+
+	round := transcript.Rounds[0]
+	require.Len(t, round.Fields, 2)
+	assert.Equal(t, game.RoleMaster, round.Fields[0].Role)
+	assert.Equal(t, fleets[0], round.Fields[0].Ships)
+	assert.Equal(t, game.RoleSlave, round.Fields[1].Role)
+	assert.Equal(t, fleets[1], round.Fields[1].Ships)
+
+	// Next match gets the same fleets, and so does the one with the
+	// same seed, but played by another judge.
+	j.Judge(context.Background(), newBot(t, "parity"), newBot(t, "hunt-target"))
+	require.Len(t, fleets, 4)
+	assert.Equal(t, fleets[:2], fleets[2:])
+
+	var again [][]field.Ship
+	j.Placements = recordedPlacements(1, &again)
+	j.Judge(context.Background(), newBot(t, "parity"), newBot(t, "hunt-target"))
+	assert.Equal(t, fleets[:2], again)
+	assert.NotEqual(t, again[0], again[1])
+}
+
+func TestJudge_FleetNotLoaded(t *testing.T) {
+	var fleets [][]field.Ship
+
+	j := newJudge()
+	j.Configurations = judge.FixedConfiguration(placementConfiguration)
+	j.Placements = recordedPlacements(1, &fleets)
+
+	// Bot pretends to load the field, but places its own.
+	broken := &brokenFactory{newBot(t, "random"), replace("load ", "ok"), nil}
+
+	verdict, _ := j.Judge(context.Background(), newBot(t, "random"), broken)
+	assert.Equal(t, judge.MasterWon, verdict.Winner)
+	assert.Equal(t, judge.CodeFleetNotLoaded, verdict.Code)
+}
+
+func TestJudge_PlacementRequiresConfiguration(t *testing.T) {
+	j := newJudge()
+	j.Placements = bots.Placements(1)
+
+	verdict, _ := j.Judge(context.Background(), newBot(t, "random"), newBot(t, "random"))
+	assert.Equal(t, judge.Tie, verdict.Winner)
+	assert.Equal(t, judge.JudgeError, verdict.Reason)
+}
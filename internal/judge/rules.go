@@ -0,0 +1,189 @@
+package judge
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+// Name of the rule set used when none is specified.
+const DefaultRules string = "classic"
+
+// How a round has ended for one of the players.
+type Outcome struct {
+	// Player that has either won or failed.
+	Role game.Role
+
+	// Nil if the player won.
+	Err error
+
+	// Classification of `Err`, `Ok` if the player won.
+	Reason Reason
+
+	// Whether the configuration was chosen by the judge, rather than by the master.
+	JudgeConfiguration bool
+}
+
+func (o *Outcome) Won() bool {
+	return o.Err == nil
+}
+
+// Rules of the game, consulted by the judge.
+//
+// Rules must be stateless, as the same rules are shared by all matches.
+type Rules interface {
+	// Checks configuration chosen by the master.
+	ValidateConfiguration(field.Configuration) error
+
+	// Returns role that shoots first.
+	FirstShooter() game.Role
+
+	// Returns whether the shooter shoots again after the given result.
+	KeepsTurn(field.ShootResult) bool
+
+	// Returns whether the player that owns the field has lost.
+	Lost(field.Field) bool
+
+	// Decides the result of a match given how its first round has ended.
+	//
+	// If `breaker` is true, the result is ignored, and the breaker round
+	// is conducted instead. In the breaker round, the master plays as
+	// slave against the judge, which uses the master's own field.
+	Adjudicate(o Outcome) (result Result, breaker bool)
+
+	// Decides the result of a match given how its breaker round has ended.
+	// Roles are the ones of the breaker round, i.e. the slave is the
+	// original master.
+	AdjudicateBreaker(o Outcome) Result
+
+	// Decides the result of a match whose first round has reached the
+	// shot limit, given how many ship cells each player has destroyed.
+	//
+	// If the breaker round reaches the limit, or the judge fires all of
+	// its breaker shots, it is adjudicated by `AdjudicateBreaker`, as a
+	// failure of the original master, no matter whose turn it is.
+	AdjudicateShotLimit(masterDestroyed, slaveDestroyed int64) Result
+}
+
+// Rules as they were in the first tournament.
+//
+//   - Slave shoots first, and hitting a ship grants another shot.
+//   - If player wins, he wins.
+//   - If player errors out, the other player wins.
+//   - If slave loses due to ML, breaker round is
+//     conducted to determine whether the master
+//     is able to handle its own configuration.
+//   - If he can't, it's a tie, unless it's due to running out of time.
+//     Reaching the shot limit in the breaker round, or the judge firing
+//     all of its breaker shots, counts as not being able to, and is a tie.
+//   - If the configuration was chosen by the judge, there's no one to
+//     blame for it, so slave losing due to ML just loses.
+//   - If the shot limit is reached, player that has destroyed
+//     more ship cells wins, and it's a tie if they are even.
+type ClassicRules struct{}
+
+func (ClassicRules) ValidateConfiguration(conf field.Configuration) error {
+	return conf.IsValid()
+}
+
+func (ClassicRules) FirstShooter() game.Role {
+	return game.RoleSlave
+}
+
+func (ClassicRules) KeepsTurn(result field.ShootResult) bool {
+	return result != field.Miss
+}
+
+func (ClassicRules) Lost(f field.Field) bool {
+	return f.AllDead()
+}
+
+func (ClassicRules) Adjudicate(o Outcome) (Result, bool) {
+	if o.Won() {
+		return ResultFromWinner(o.Role), false
+	}
+
+	if o.Reason != MemoryLimit || o.JudgeConfiguration {
+		return ResultFromWinner(o.Role.Other()), false
+	}
+
+	if o.Role == game.RoleMaster {
+		return SlaveWon, false
+	}
+
+	return Tie, true
+}
+
+func (ClassicRules) AdjudicateBreaker(o Outcome) Result {
+	// Either the master won, or the judge has failed.
+	if o.Won() || o.Role != game.RoleSlave {
+		return MasterWon
+	}
+
+	// Running out of time is not an excuse.
+	if o.Reason == Timeout {
+		return SlaveWon
+	}
+
+	return Tie
+}
+
+func (ClassicRules) AdjudicateShotLimit(masterDestroyed, slaveDestroyed int64) Result {
+	switch {
+	case masterDestroyed > slaveDestroyed:
+		return MasterWon
+	case slaveDestroyed > masterDestroyed:
+		return SlaveWon
+	default:
+		return Tie
+	}
+}
+
+var (
+	rulesMu  sync.RWMutex
+	rulesets = map[string]Rules{
+		DefaultRules: ClassicRules{},
+	}
+)
+
+// Makes rules selectable by name. Registering the same name twice panics.
+func RegisterRules(name string, rules Rules) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
+	if _, ok := rulesets[name]; ok {
+		panic("rules registered twice: " + name)
+	}
+
+	rulesets[name] = rules
+}
+
+// Returns rules registered under the given name, or
+// the default ones if the name is empty.
+func LookupRules(name string) (Rules, error) {
+	if name == "" {
+		name = DefaultRules
+	}
+
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	rules, ok := rulesets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown rules: %q", name)
+	}
+
+	return rules, nil
+}
+
+// Returns names of all registered rule sets, sorted.
+func RulesNames() []string {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	return slices.Sorted(maps.Keys(rulesets))
+}
@@ -0,0 +1,71 @@
+package judge_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+func TestClassicRules_Adjudicate(t *testing.T) {
+	rules := judge.ClassicRules{}
+	err := errors.New("failed")
+
+	cases := []struct {
+		outcome judge.Outcome
+		result  judge.Result
+		breaker bool
+	}{
+		{judge.Outcome{Role: game.RoleMaster}, judge.MasterWon, false},
+		{judge.Outcome{Role: game.RoleSlave}, judge.SlaveWon, false},
+		{judge.Outcome{Role: game.RoleMaster, Err: err, Reason: judge.RuntimeError}, judge.SlaveWon, false},
+		{judge.Outcome{Role: game.RoleSlave, Err: err, Reason: judge.Timeout}, judge.MasterWon, false},
+		{judge.Outcome{Role: game.RoleMaster, Err: err, Reason: judge.MemoryLimit}, judge.SlaveWon, false},
+		{judge.Outcome{Role: game.RoleSlave, Err: err, Reason: judge.MemoryLimit}, judge.Tie, true},
+		{judge.Outcome{Role: game.RoleSlave, Err: err, Reason: judge.MemoryLimit, JudgeConfiguration: true}, judge.MasterWon, false},
+	}
+
+	for _, c := range cases {
+		result, breaker := rules.Adjudicate(c.outcome)
+		assert.Equal(t, c.breaker, breaker, "%+v", c.outcome)
+		if !breaker {
+			assert.Equal(t, c.result, result, "%+v", c.outcome)
+		}
+	}
+}
+
+func TestClassicRules_AdjudicateBreaker(t *testing.T) {
+	rules := judge.ClassicRules{}
+	err := errors.New("failed")
+
+	// Slave of the breaker round is the original master.
+	assert.Equal(t, judge.MasterWon, rules.AdjudicateBreaker(judge.Outcome{Role: game.RoleSlave}))
+	assert.Equal(t, judge.MasterWon, rules.AdjudicateBreaker(judge.Outcome{Role: game.RoleMaster}))
+	assert.Equal(t, judge.Tie, rules.AdjudicateBreaker(judge.Outcome{Role: game.RoleSlave, Err: err, Reason: judge.MemoryLimit}))
+	assert.Equal(t, judge.SlaveWon, rules.AdjudicateBreaker(judge.Outcome{Role: game.RoleSlave, Err: err, Reason: judge.Timeout}))
+}
+
+func TestClassicRules_AdjudicateShotLimit(t *testing.T) {
+	rules := judge.ClassicRules{}
+
+	assert.Equal(t, judge.MasterWon, rules.AdjudicateShotLimit(5, 3))
+	assert.Equal(t, judge.SlaveWon, rules.AdjudicateShotLimit(0, 1))
+	assert.Equal(t, judge.Tie, rules.AdjudicateShotLimit(4, 4))
+}
+
+func TestLookupRules(t *testing.T) {
+	rules, err := judge.LookupRules("")
+	require.NoError(t, err)
+	assert.Equal(t, judge.ClassicRules{}, rules)
+
+	_, err = judge.LookupRules("nonexistent")
+	assert.Error(t, err)
+
+	judge.RegisterRules("test", judge.ClassicRules{})
+	assert.Contains(t, judge.RulesNames(), "test")
+	assert.Panics(t, func() { judge.RegisterRules("test", judge.ClassicRules{}) })
+}
@@ -0,0 +1,179 @@
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+)
+
+type SeriesWinner int
+
+// Values mirror the ones of `Result`, as the first
+// player is the master of the first game.
+const (
+	SeriesTie SeriesWinner = iota
+	FirstWon
+	SecondWon
+)
+
+func (w SeriesWinner) String() string {
+	switch w {
+	case SeriesTie:
+		return "tie"
+	case FirstWon:
+		return "first"
+	case SecondWon:
+		return "second"
+	default:
+		panic("invalid series winner")
+	}
+}
+
+func (w SeriesWinner) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.String())
+}
+
+func (w *SeriesWinner) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	for _, winner := range []SeriesWinner{SeriesTie, FirstWon, SecondWon} {
+		if winner.String() == str {
+			*w = winner
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid series winner: %q", str)
+}
+
+type SeriesGame struct {
+	Verdict
+
+	// Whether the first player was the master.
+	FirstIsMaster bool `json:"first_is_master"`
+
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+
+	Transcript *Transcript `json:"-"`
+}
+
+// Returns scores of the first and the second players in this game.
+func (g *SeriesGame) Score() [2]float64 {
+	var master, slave float64
+
+	switch g.Winner {
+	case MasterWon:
+		master = 1
+	case SlaveWon:
+		slave = 1
+	default:
+		master, slave = 0.5, 0.5
+	}
+
+	if g.FirstIsMaster {
+		return [2]float64{master, slave}
+	}
+	return [2]float64{slave, master}
+}
+
+type SeriesResult struct {
+	Winner SeriesWinner `json:"winner"`
+
+	// Scores of the first and the second players,
+	// 1 for each win and 0.5 for each tie.
+	Score [2]float64 `json:"score"`
+
+	Games []SeriesGame `json:"games"`
+}
+
+func (r *SeriesResult) add(g SeriesGame) {
+	score := g.Score()
+	r.Score[0] += score[0]
+	r.Score[1] += score[1]
+
+	switch {
+	case r.Score[0] > r.Score[1]:
+		r.Winner = FirstWon
+	case r.Score[1] > r.Score[0]:
+		r.Winner = SecondWon
+	default:
+		r.Winner = SeriesTie
+	}
+
+	r.Games = append(r.Games, g)
+}
+
+// Best-of-N series of matches between two players.
+//
+// Players swap roles every game, with the first player being the
+// master of the first game, so that neither of them benefits from
+// choosing the configuration more often. That's why an even number
+// of games is the fairest.
+type Series struct {
+	Judge *Judge
+
+	// Maximum number of games to play.
+	Games int
+
+	// Called after each game. May be nil.
+	OnGame func(SeriesGame)
+}
+
+// Plays the series until either all the games are played or the
+// winner is known for certain, i.e. the loser can't catch up even
+// by winning all the remaining games.
+//
+// If the context is cancelled, the game that was being played is
+// discarded, and games played so far are returned with the cause.
+// Same goes for a game the judge has failed.
+func (s *Series) Play(ctx context.Context, first, second game.PlayerFactory) (SeriesResult, error) {
+	result := SeriesResult{
+		Games: []SeriesGame{},
+	}
+
+	for i := range s.Games {
+		g := SeriesGame{
+			FirstIsMaster: i%2 == 0,
+			StartedAt:     time.Now(),
+		}
+
+		if g.FirstIsMaster {
+			g.Verdict, g.Transcript = s.Judge.Judge(ctx, first, second)
+		} else {
+			g.Verdict, g.Transcript = s.Judge.Judge(ctx, second, first)
+		}
+
+		g.FinishedAt = time.Now()
+
+		if ctx.Err() != nil {
+			return result, context.Cause(ctx)
+		}
+
+		// Judge would fail the same way in every game.
+		if g.Reason == JudgeError {
+			return result, errors.New(g.Details)
+		}
+
+		result.add(g)
+
+		if s.OnGame != nil {
+			s.OnGame(g)
+		}
+
+		remaining := float64(s.Games - i - 1)
+		lead := result.Score[0] - result.Score[1]
+		if lead > remaining || -lead > remaining {
+			break
+		}
+	}
+
+	return result, nil
+}
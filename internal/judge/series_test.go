@@ -0,0 +1,123 @@
+package judge_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+// Player that crashes on the first command. Since master is created
+// first, it loses either as master or as slave against okPlayer.
+type crashingPlayer struct{}
+
+func (crashingPlayer) SendCommand(string) (string, error) {
+	return "", &game.ErrorTerminated{Reason: game.ReasonRuntimeError}
+}
+
+func (crashingPlayer) RetrieveField(field.Configuration) (field.Field, error) {
+	return nil, errors.New("no field")
+}
+
+func (crashingPlayer) ProvideField(field.Configuration, field.Field) error {
+	return nil
+}
+
+func (crashingPlayer) Close() error {
+	return nil
+}
+
+// Player that agrees with everything.
+type okPlayer struct{}
+
+func (okPlayer) SendCommand(string) (string, error) {
+	return "ok", nil
+}
+
+func (okPlayer) RetrieveField(field.Configuration) (field.Field, error) {
+	return nil, errors.New("no field")
+}
+
+func (okPlayer) ProvideField(field.Configuration, field.Field) error {
+	return nil
+}
+
+func (okPlayer) Close() error {
+	return nil
+}
+
+type factory struct {
+	player game.Player
+}
+
+func (f factory) NewPlayer(context.Context) game.Player {
+	return f.player
+}
+
+func newSeries(games int) *judge.Series {
+	return &judge.Series{
+		Judge: &judge.Judge{
+			PlayerTimeout: time.Second,
+			GlobalTimeout: time.Second,
+		},
+		Games: games,
+	}
+}
+
+func TestSeries_EarlyStop(t *testing.T) {
+	series := newSeries(5)
+
+	var played int
+	series.OnGame = func(judge.SeriesGame) {
+		played++
+	}
+
+	result, err := series.Play(context.Background(), factory{crashingPlayer{}}, factory{okPlayer{}})
+	require.NoError(t, err)
+
+	// After 3 losses out of 5 games, the first player can't catch up.
+	require.Len(t, result.Games, 3)
+	assert.Equal(t, 3, played)
+	assert.Equal(t, judge.SecondWon, result.Winner)
+	assert.Equal(t, [2]float64{0, 3}, result.Score)
+
+	for i, g := range result.Games {
+		assert.Equal(t, i%2 == 0, g.FirstIsMaster)
+		assert.NotNil(t, g.Transcript)
+	}
+}
+
+func TestSeries_Tie(t *testing.T) {
+	// Master always crashes first, so each player wins as slave.
+	result, err := newSeries(4).Play(context.Background(), factory{crashingPlayer{}}, factory{crashingPlayer{}})
+	require.NoError(t, err)
+
+	require.Len(t, result.Games, 4)
+	assert.Equal(t, judge.SeriesTie, result.Winner)
+	assert.Equal(t, [2]float64{2, 2}, result.Score)
+}
+
+func TestSeries_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := newSeries(3).Play(ctx, factory{okPlayer{}}, factory{okPlayer{}})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, result.Games)
+}
+
+func TestSeries_JudgeError(t *testing.T) {
+	series := newSeries(3)
+	series.Judge.Configurations = judge.FixedConfiguration(field.Configuration{W: 10, H: 10})
+
+	result, err := series.Play(context.Background(), factory{okPlayer{}}, factory{okPlayer{}})
+	assert.ErrorContains(t, err, "is invalid")
+	assert.Empty(t, result.Games)
+}
@@ -0,0 +1,67 @@
+package judge
+
+import (
+	"time"
+
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+// What a single player has done during a round.
+type PlayerStats struct {
+	// Shots that were answered properly, i.e. `Hits + Kills + Misses`.
+	Shots  int64 `json:"shots"`
+	Hits   int64 `json:"hits"`
+	Kills  int64 `json:"kills"`
+	Misses int64 `json:"misses"`
+
+	// Distinct ship cells destroyed, i.e. hits and kills
+	// not counting cells that were already shot.
+	Destroyed int64 `json:"destroyed"`
+
+	// Time used by the player, as measured by its stopwatch.
+	Time time.Duration `json:"time"`
+}
+
+func (s *PlayerStats) addShot(result field.ShootResult) {
+	s.Shots++
+
+	switch result {
+	case field.Hit:
+		s.Hits++
+	case field.Kill:
+		s.Kills++
+	case field.Miss:
+		s.Misses++
+	}
+}
+
+type RoundStats struct {
+	// Absent if the round has ended before the configuration was known.
+	Configuration *field.Configuration `json:"configuration,omitempty"`
+
+	// Turns started, where each turn lasts until the shooter
+	// passes the turn to the other player.
+	Turns int64 `json:"turns"`
+
+	Master PlayerStats `json:"master"`
+	Slave  PlayerStats `json:"slave"`
+}
+
+// Same as for `RoundTranscript`, master role is played by
+// the judge, and slave role is played by the original master.
+type BreakerStats struct {
+	RoundStats
+
+	// Result of the match as adjudicated after the breaker round.
+	Result Result `json:"result"`
+
+	// How the breaker round has ended, `OK` if either player has won.
+	Reason Reason `json:"reason"`
+}
+
+type MatchStats struct {
+	RoundStats
+
+	// Present only if the breaker round was played.
+	Breaker *BreakerStats `json:"breaker,omitempty"`
+}
@@ -0,0 +1,130 @@
+package judge_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/bots"
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+// Bot that runs out of memory as soon as it is started.
+type hungryPlayer struct {
+	game.Player
+}
+
+func (p hungryPlayer) SendCommand(cmd string) (string, error) {
+	if cmd == "start" {
+		return "", game.ErrTerminatedMemoryLimit
+	}
+	return p.Player.SendCommand(cmd)
+}
+
+type hungryFactory struct {
+	game.PlayerFactory
+}
+
+func (f hungryFactory) NewPlayer(ctx context.Context) game.Player {
+	return hungryPlayer{f.PlayerFactory.NewPlayer(ctx)}
+}
+
+func newBot(t *testing.T, name string) game.PlayerFactory {
+	f, err := bots.NewPlayerFactory(name, 1)
+	require.NoError(t, err)
+	return f
+}
+
+func newJudge() *judge.Judge {
+	return &judge.Judge{
+		PlayerTimeout: 10 * time.Second,
+		GlobalTimeout: 10 * time.Second,
+	}
+}
+
+func TestJudge_Stats(t *testing.T) {
+	verdict, _ := newJudge().Judge(context.Background(), newBot(t, "density"), newBot(t, "random"))
+	require.Equal(t, judge.Ok, verdict.Reason, verdict.Details)
+	require.NotNil(t, verdict.Stats)
+
+	stats := verdict.Stats
+	assert.Nil(t, stats.Breaker)
+	assert.Equal(t, &bots.DefaultConfiguration, stats.Configuration)
+
+	for _, player := range []judge.PlayerStats{stats.Master, stats.Slave} {
+		assert.Equal(t, player.Shots, player.Hits+player.Kills+player.Misses)
+		assert.Positive(t, player.Time)
+	}
+
+	winner := stats.Master
+	if verdict.Winner == judge.SlaveWon {
+		winner = stats.Slave
+	}
+	assert.EqualValues(t, 10, winner.Kills)
+
+	// Every turn but the last one ends with a miss.
+	assert.Equal(t, stats.Master.Misses+stats.Slave.Misses+1, stats.Turns)
+}
+
+func TestJudge_StatsBreaker(t *testing.T) {
+	verdict, _ := newJudge().Judge(context.Background(), newBot(t, "density"), hungryFactory{newBot(t, "random")})
+	require.NotNil(t, verdict.Stats)
+
+	stats := verdict.Stats
+	assert.Equal(t, judge.Ok, stats.Breaker.Reason)
+	assert.Equal(t, verdict.Winner, stats.Breaker.Result)
+	assert.Equal(t, judge.MasterWon, verdict.Winner)
+
+	// Main round has ended before anyone could shoot.
+	assert.Zero(t, stats.Turns)
+	assert.Zero(t, stats.Slave.Shots)
+
+	require.NotNil(t, stats.Breaker.Configuration)
+	assert.Positive(t, stats.Breaker.Turns)
+	assert.Positive(t, stats.Breaker.Slave.Shots+stats.Breaker.Master.Shots)
+	assert.Zero(t, stats.Breaker.Master.Time)
+}
+
+// Bot that keeps shooting at the same cell.
+type stubbornPlayer struct {
+	game.Player
+}
+
+func (p stubbornPlayer) SendCommand(cmd string) (string, error) {
+	if cmd == "shot" {
+		return "0 0", nil
+	}
+	return p.Player.SendCommand(cmd)
+}
+
+type stubbornFactory struct {
+	game.PlayerFactory
+}
+
+func (f stubbornFactory) NewPlayer(ctx context.Context) game.Player {
+	return stubbornPlayer{f.PlayerFactory.NewPlayer(ctx)}
+}
+
+func TestJudge_ShotLimit(t *testing.T) {
+	j := newJudge()
+	j.MaxShots = 60
+
+	verdict, _ := j.Judge(context.Background(), newBot(t, "density"), stubbornFactory{newBot(t, "random")})
+	require.Equal(t, judge.ShotLimit, verdict.Reason, verdict.Details)
+
+	stats := verdict.Stats
+	assert.EqualValues(t, 60, stats.Master.Shots+stats.Slave.Shots)
+	assert.LessOrEqual(t, stats.Slave.Destroyed, int64(1))
+	assert.Equal(t, judge.ClassicRules{}.AdjudicateShotLimit(stats.Master.Destroyed, stats.Slave.Destroyed), verdict.Winner)
+}
+
+func TestDefaultMaxShots(t *testing.T) {
+	assert.EqualValues(t, 200, judge.DefaultMaxShots(bots.DefaultConfiguration))
+	assert.EqualValues(t, 1_000_000, judge.DefaultMaxShots(field.Configuration{W: 1_000_000_000, H: 1_000_000_000}))
+	assert.EqualValues(t, 1_000_000, judge.DefaultMaxShots(field.Configuration{W: 1 << 62, H: 1 << 62}))
+}
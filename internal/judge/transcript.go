@@ -0,0 +1,127 @@
+package judge
+
+import (
+	"time"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+// Single command sent to a player and the player's response to it.
+type Exchange struct {
+	Role     game.Role `json:"role"`
+	Command  string    `json:"command"`
+	Response string    `json:"response"`
+	Error    string    `json:"error,omitempty"`
+
+	// Time when the command was sent.
+	Time time.Time `json:"time"`
+
+	// Total time used by the player so far, as measured by its stopwatch.
+	Used time.Duration `json:"used"`
+}
+
+// Field retrieved from a player after `dump`.
+type FieldDump struct {
+	Role  game.Role    `json:"role"`
+	Ships []field.Ship `json:"ships,omitempty"`
+	Error string       `json:"error,omitempty"`
+	Time  time.Time    `json:"time"`
+}
+
+type RoundTranscript struct {
+	// In the breaker round, master role is played by the judge
+	// itself, and slave role is played by the original master.
+	Breaker bool `json:"breaker"`
+
+	Configuration *field.Configuration `json:"configuration,omitempty"`
+	Exchanges     []Exchange           `json:"exchanges"`
+	Fields        []FieldDump          `json:"fields"`
+
+	// Set if there were more exchanges than recorded, in which case
+	// only the first ones are kept.
+	Truncated bool `json:"truncated,omitempty"`
+
+	maxExchanges int
+}
+
+// Full record of a match, i.e. of all rounds played in it.
+type Transcript struct {
+	Rounds []*RoundTranscript `json:"rounds"`
+}
+
+func (t *Transcript) newRound(breaker bool, maxExchanges int) *RoundTranscript {
+	round := &RoundTranscript{
+		Breaker:      breaker,
+		Exchanges:    []Exchange{},
+		Fields:       []FieldDump{},
+		maxExchanges: maxExchanges,
+	}
+	t.Rounds = append(t.Rounds, round)
+	return round
+}
+
+// Player that records everything passing through it into a transcript.
+type recordingPlayer struct {
+	game.Player
+	role       game.Role
+	transcript *RoundTranscript
+}
+
+func newRecordingPlayer(player game.Player, role game.Role, transcript *RoundTranscript) *recordingPlayer {
+	return &recordingPlayer{
+		player,
+		role,
+		transcript,
+	}
+}
+
+func (p *recordingPlayer) elapsed() time.Duration {
+	if sw, ok := p.Player.(*game.StopwatchPlayer); ok {
+		return sw.Elapsed()
+	}
+	return 0
+}
+
+func (p *recordingPlayer) SendCommand(cmd string) (string, error) {
+	sent := time.Now()
+	resp, err := p.Player.SendCommand(cmd)
+
+	if len(p.transcript.Exchanges) >= p.transcript.maxExchanges {
+		p.transcript.Truncated = true
+		return resp, err
+	}
+
+	exchange := Exchange{
+		Role:     p.role,
+		Command:  cmd,
+		Response: resp,
+		Time:     sent,
+		Used:     p.elapsed(),
+	}
+	if err != nil {
+		exchange.Error = err.Error()
+	}
+
+	p.transcript.Exchanges = append(p.transcript.Exchanges, exchange)
+
+	return resp, err
+}
+
+func (p *recordingPlayer) RetrieveField(conf field.Configuration) (field.Field, error) {
+	dump := FieldDump{
+		Role: p.role,
+		Time: time.Now(),
+	}
+
+	f, err := p.Player.RetrieveField(conf)
+	if err != nil {
+		dump.Error = err.Error()
+	} else {
+		dump.Ships = sortedShips(f)
+	}
+
+	p.transcript.Fields = append(p.transcript.Fields, dump)
+
+	return f, err
+}
@@ -0,0 +1,29 @@
+package judge_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+func TestJudge_TranscriptTruncated(t *testing.T) {
+	j := newJudge()
+	j.MaxExchanges = 30
+
+	verdict, transcript := j.Judge(context.Background(), newBot(t, "density"), newBot(t, "random"))
+	require.Equal(t, judge.Ok, verdict.Reason, verdict.Details)
+
+	// Match goes on past the limit, only the transcript stops.
+	round := transcript.Rounds[0]
+	assert.True(t, round.Truncated)
+	assert.Len(t, round.Exchanges, 30)
+	assert.Greater(t, verdict.Stats.Master.Shots+verdict.Stats.Slave.Shots, int64(30))
+
+	verdict, transcript = newJudge().Judge(context.Background(), newBot(t, "density"), newBot(t, "random"))
+	require.Equal(t, judge.Ok, verdict.Reason, verdict.Details)
+	assert.False(t, transcript.Rounds[0].Truncated)
+}
@@ -0,0 +1,29 @@
+package process
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+)
+
+type PlayerFactory struct {
+	path string
+	args []string
+}
+
+func NewPlayerFactory(path string, args ...string) *PlayerFactory {
+	return &PlayerFactory{
+		path,
+		args,
+	}
+}
+
+func (f *PlayerFactory) NewPlayer(ctx context.Context) game.Player {
+	p, err := NewProcessPlayer(ctx, f.path, f.args...)
+	if err != nil {
+		return &game.FailedPlayer{Err: fmt.Errorf("failed to create player: %w", err)}
+	}
+
+	return p
+}
@@ -0,0 +1,222 @@
+package process
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+)
+
+// Maps the way the process has exited onto the termination reason.
+//
+// Limits aren't enforced here, so it's assumed that processes are
+// killed by the OOM killer (or by the `ulimit` of a wrapper) on
+// memory limit and receive SIGXCPU on cpu time limit.
+func terminationReason(state *os.ProcessState) game.TerminationReason {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if ok && status.Signaled() {
+		switch status.Signal() {
+		case syscall.SIGKILL:
+			return game.ReasonMemoryLimit
+		case syscall.SIGXCPU:
+			return game.ReasonTimeLimit
+		default:
+			return game.ReasonRuntimeError
+		}
+	}
+
+	switch state.ExitCode() {
+	case 0:
+		return game.ReasonNormal
+	case 137:
+		// Same as in docker, e.g. if the player is wrapped by a shell.
+		return game.ReasonMemoryLimit
+	default:
+		return game.ReasonRuntimeError
+	}
+}
+
+// Player running as a local process.
+//
+// Each player gets its own temporary directory, and fields are dumped
+// to and loaded from there instead of `game.DumpPath` and `game.LoadPath`,
+// so that players don't overwrite each other's fields.
+type ProcessPlayer struct {
+	ctx     context.Context
+	cmd     *exec.Cmd
+	stdin   *os.File
+	stdout  *os.File
+	scanner *bufio.Scanner
+	dir     string
+
+	exited  chan struct{}
+	waitErr error
+}
+
+func NewProcessPlayer(ctx context.Context, path string, args ...string) (*ProcessPlayer, error) {
+	dir, err := os.MkdirTemp("", "itmournament-player-")
+	if err != nil {
+		return nil, err
+	}
+
+	// Pipes are created manually, so that `Wait` doesn't close
+	// stdout before everything written to it is read.
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		stdinR.Close()
+		stdinW.Close()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = stdinR
+	cmd.Stdout = stdoutW
+
+	// Whole process group is killed, so that no children
+	// are left holding stdout open.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	err = cmd.Start()
+
+	// Child has its own copies now.
+	stdinR.Close()
+	stdoutW.Close()
+
+	if err != nil {
+		stdinW.Close()
+		stdoutR.Close()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(stdoutR)
+	scanner.Split(bufio.ScanLines)
+
+	p := &ProcessPlayer{
+		ctx:     ctx,
+		cmd:     cmd,
+		stdin:   stdinW,
+		stdout:  stdoutR,
+		scanner: scanner,
+		dir:     dir,
+		exited:  make(chan struct{}),
+	}
+
+	go func() {
+		p.waitErr = cmd.Wait()
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		close(p.exited)
+	}()
+
+	return p, nil
+}
+
+func (p *ProcessPlayer) fieldPath() string {
+	return filepath.Join(p.dir, "field.txt")
+}
+
+func (p *ProcessPlayer) fleetPath() string {
+	return filepath.Join(p.dir, "fleet.txt")
+}
+
+// Waits for the process to exit and returns the reason it did.
+func (p *ProcessPlayer) terminated() error {
+	<-p.exited
+
+	// Process was killed by us, not by itself.
+	if p.ctx.Err() != nil {
+		return context.Cause(p.ctx)
+	}
+
+	var exitErr *exec.ExitError
+	if p.waitErr != nil && !errors.As(p.waitErr, &exitErr) {
+		return p.waitErr
+	}
+
+	return &game.ErrorTerminated{Reason: terminationReason(p.cmd.ProcessState)}
+}
+
+func (p *ProcessPlayer) SendCommand(cmd string) (string, error) {
+	if strings.HasPrefix(cmd, "dump ") {
+		cmd = "dump " + p.fieldPath()
+	}
+	if strings.HasPrefix(cmd, "load ") {
+		cmd = "load " + p.fleetPath()
+	}
+
+	cmd += "\n"
+
+	if _, err := p.stdin.Write([]byte(cmd)); err != nil {
+		return "", p.terminated()
+	}
+
+	if !p.scanner.Scan() {
+		if err := p.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", p.terminated()
+	}
+
+	return p.scanner.Text(), nil
+}
+
+func (p *ProcessPlayer) RetrieveField(conf field.Configuration) (field.Field, error) {
+	r, err := os.Open(p.fieldPath())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	f := field.NewShipField(0)
+	if err := field.ReadShips(f, r, conf); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (p *ProcessPlayer) ProvideField(conf field.Configuration, f field.Field) error {
+	w, err := os.Create(p.fleetPath())
+	if err != nil {
+		return err
+	}
+
+	if err := field.WriteShips(w, conf, f.Ships()); err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+func (p *ProcessPlayer) Close() error {
+	p.stdin.Close()
+
+	select {
+	case <-p.exited:
+	default:
+		syscall.Kill(-p.cmd.Process.Pid, syscall.SIGKILL)
+		<-p.exited
+	}
+
+	p.stdout.Close()
+
+	return os.RemoveAll(p.dir)
+}
@@ -0,0 +1,155 @@
+package process_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/process"
+)
+
+func getPlayer(t testing.TB, ctx context.Context) game.Player {
+	player, err := process.NewProcessPlayer(ctx, "sh", "testdata/player.sh")
+	require.NoError(t, err)
+	t.Cleanup(func() { player.Close() })
+
+	return player
+}
+
+func Test_Echo(t *testing.T) {
+	player := getPlayer(t, context.Background())
+
+	for _, str := range []string{"1", "2", "3"} {
+		resp, err := player.SendCommand("echo " + str)
+		require.NoError(t, err)
+		assert.Equal(t, str, resp)
+	}
+}
+
+func Test_Exit(t *testing.T) {
+	player := getPlayer(t, context.Background())
+
+	_, err := player.SendCommand("exit 0")
+	assert.ErrorIs(t, err, &game.ErrorTerminated{Reason: game.ReasonNormal})
+
+	// Pipe is broken now.
+	_, err = player.SendCommand("echo 1")
+	assert.ErrorIs(t, err, &game.ErrorTerminated{Reason: game.ReasonNormal})
+}
+
+func Test_RuntimeError(t *testing.T) {
+	player := getPlayer(t, context.Background())
+
+	_, err := player.SendCommand("echo asd")
+	assert.ErrorIs(t, err, &game.ErrorTerminated{Reason: game.ReasonRuntimeError})
+}
+
+func Test_Signals(t *testing.T) {
+	cases := map[string]game.TerminationReason{
+		"KILL": game.ReasonMemoryLimit,
+		"XCPU": game.ReasonTimeLimit,
+		"SEGV": game.ReasonRuntimeError,
+	}
+
+	for signal, reason := range cases {
+		t.Run(signal, func(t *testing.T) {
+			player := getPlayer(t, context.Background())
+
+			_, err := player.SendCommand("signal " + signal)
+			assert.ErrorIs(t, err, &game.ErrorTerminated{Reason: reason})
+		})
+	}
+}
+
+func Test_Cancelled(t *testing.T) {
+	cause := errors.New("too slow")
+
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 100*time.Millisecond, cause)
+	defer cancel()
+
+	player := getPlayer(t, ctx)
+
+	_, err := player.SendCommand("sleep 10")
+	assert.ErrorIs(t, err, cause)
+}
+
+func Test_GetField(t *testing.T) {
+	player := getPlayer(t, context.Background())
+
+	// Dump is redirected to the player's own directory.
+	requested := filepath.Join(t.TempDir(), "field.txt")
+
+	resp, err := player.SendCommand("dump " + requested)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.NoFileExists(t, requested)
+
+	f, err := player.RetrieveField(field.Configuration{
+		W:     10,
+		H:     10,
+		Sizes: []int64{1, 1, 1, 1},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, field.Kill, f.Shoot(0, 0))
+
+	assert.Equal(t, field.Hit, f.Shoot(0, 2))
+	assert.Equal(t, field.Kill, f.Shoot(1, 2))
+
+	assert.Equal(t, field.Hit, f.Shoot(0, 4))
+	assert.Equal(t, field.Hit, f.Shoot(1, 4))
+	assert.Equal(t, field.Kill, f.Shoot(2, 4))
+
+	assert.Equal(t, field.Hit, f.Shoot(0, 6))
+	assert.Equal(t, field.Hit, f.Shoot(1, 6))
+	assert.Equal(t, field.Hit, f.Shoot(2, 6))
+	assert.Equal(t, field.Kill, f.Shoot(3, 6))
+
+	assert.True(t, f.AllDead())
+}
+
+func Test_ProvideField(t *testing.T) {
+	player := getPlayer(t, context.Background())
+
+	conf := field.Configuration{W: 5, H: 4, Sizes: []int64{0, 0, 1, 0}}
+	f := field.NewShipField(0)
+	require.NoError(t, f.Load(conf, slices.Values([]field.Ship{{X: 1, Y: 0, Size: 3, IsVert: true}})))
+
+	require.NoError(t, player.ProvideField(conf, f))
+
+	// Load is redirected to the player's own directory.
+	requested := filepath.Join(t.TempDir(), "fleet.txt")
+
+	resp, err := player.SendCommand("load " + requested)
+	require.NoError(t, err)
+	assert.Equal(t, "5 4 3 v 1 0 ", resp)
+}
+
+func Test_ClosedByJudge(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	j := &judge.Judge{
+		PlayerTimeout: 200 * time.Millisecond,
+		GlobalTimeout: 5 * time.Second,
+	}
+
+	factory := process.NewPlayerFactory("sh", "testdata/player.sh")
+	verdict, _ := j.Judge(context.Background(), factory, factory)
+	require.NotEqual(t, judge.Ok, verdict.Reason)
+
+	// Temporary directories of all players are removed.
+	entries, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
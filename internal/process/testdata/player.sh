@@ -0,0 +1,30 @@
+#!/bin/sh
+
+while read -r cmd args; do
+    case "$cmd" in
+        echo)
+            case "$args" in
+                ''|*[!0-9]*) exit 1 ;;
+            esac
+            echo "$args"
+            ;;
+        dump)
+            printf '10 10\n1 h 0 0\n2 h 0 2\n3 h 0 4\n4 h 0 6\n' > "$args"
+            echo ok
+            ;;
+        load)
+            head -n 2 "$args" | tr '\n' ' '
+            echo
+            ;;
+        signal)
+            kill -s "$args" $$
+            ;;
+        sleep)
+            sleep "$args"
+            echo ok
+            ;;
+        exit)
+            exit "$args"
+            ;;
+    esac
+done
@@ -0,0 +1,83 @@
+package rating
+
+import (
+	"math"
+)
+
+type eloPlayer struct {
+	Rating
+
+	// Fisher information of the rating, used to estimate its deviation.
+	information float64
+}
+
+// Classic Elo rating, updated after each game.
+//
+// The master advantage is added to the master's rating when computing
+// expected scores, so that players aren't rewarded for winning as
+// masters as much as for winning as slaves.
+type Elo struct {
+	K               float64
+	MasterAdvantage float64
+
+	players map[string]*eloPlayer
+}
+
+func NewElo(k, masterAdvantage float64) *Elo {
+	return &Elo{
+		K:               k,
+		MasterAdvantage: masterAdvantage,
+		players:         make(map[string]*eloPlayer),
+	}
+}
+
+func (e *Elo) player(id string) *eloPlayer {
+	p, ok := e.players[id]
+	if !ok {
+		p = &eloPlayer{
+			Rating: Rating{
+				Id:     id,
+				Rating: InitialRating,
+			},
+		}
+		e.players[id] = p
+	}
+	return p
+}
+
+func (e *Elo) Update(g Game) {
+	master := e.player(g.Master)
+	slave := e.player(g.Slave)
+
+	expected := expectedScore(master.Rating.Rating+e.MasterAdvantage, slave.Rating.Rating)
+	score := g.MasterScore()
+
+	delta := e.K * (score - expected)
+	master.Rating.Rating += delta
+	slave.Rating.Rating -= delta
+
+	information := expected * (1 - expected)
+	master.information += information
+	slave.information += information
+
+	master.addGame(score)
+	slave.addGame(1 - score)
+}
+
+// Returns ratings of all players, best first.
+//
+// Deviation is the standard error of the logistic model, i.e. it
+// shrinks as the player plays more games with uncertain outcomes.
+func (e *Elo) Ratings() []Rating {
+	ratings := make([]Rating, 0, len(e.players))
+
+	for _, p := range e.players {
+		// Every known player has played at least one game,
+		// so the information is always positive.
+		r := p.Rating
+		r.Deviation = eloScale / math.Ln10 / math.Sqrt(p.information)
+		ratings = append(ratings, r)
+	}
+
+	return sortRatings(ratings)
+}
@@ -0,0 +1,207 @@
+package rating
+
+import (
+	"math"
+)
+
+const (
+	InitialDeviation  float64 = 350
+	InitialVolatility float64 = 0.06
+
+	// Conversion factor between Glicko and Glicko-2 scales.
+	glickoScale float64 = eloScale / math.Ln10
+
+	// Convergence tolerance of the volatility iteration.
+	volatilityEpsilon float64 = 1e-6
+)
+
+type glickoPlayer struct {
+	Rating
+
+	// Glicko-2 scale.
+	mu, phi, sigma float64
+}
+
+type glickoResult struct {
+	opponent *glickoPlayer
+	score    float64
+
+	// Advantage of the player over the opponent due to roles, Glicko-2 scale.
+	advantage float64
+}
+
+// Glicko-2 rating, updated once per rating period.
+//
+// See http://www.glicko.net/glicko/glicko2.pdf.
+//
+// The master advantage is treated the same way as in `Elo`.
+type Glicko2 struct {
+	// Constrains the change of volatility over time.
+	// Reasonable values are between 0.3 and 1.2.
+	Tau float64
+
+	MasterAdvantage float64
+
+	players map[string]*glickoPlayer
+}
+
+func NewGlicko2(tau, masterAdvantage float64) *Glicko2 {
+	return &Glicko2{
+		Tau:             tau,
+		MasterAdvantage: masterAdvantage,
+		players:         make(map[string]*glickoPlayer),
+	}
+}
+
+func (g *Glicko2) player(id string) *glickoPlayer {
+	p, ok := g.players[id]
+	if !ok {
+		p = &glickoPlayer{
+			Rating: Rating{
+				Id: id,
+			},
+			phi:   InitialDeviation / glickoScale,
+			sigma: InitialVolatility,
+		}
+		g.players[id] = p
+	}
+	return p
+}
+
+// Sets the rating of the player, e.g. to carry it over from previous tournaments.
+func (g *Glicko2) SetRating(id string, rating, deviation, volatility float64) {
+	p := g.player(id)
+	p.mu = (rating - InitialRating) / glickoScale
+	p.phi = deviation / glickoScale
+	p.sigma = volatility
+}
+
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func glickoE(mu, muOpp, phiOpp float64) float64 {
+	return 1 / (1 + math.Exp(-glickoG(phiOpp)*(mu-muOpp)))
+}
+
+func (g *Glicko2) newVolatility(p *glickoPlayer, delta, v float64) float64 {
+	a := math.Log(p.sigma * p.sigma)
+	tau2 := g.Tau * g.Tau
+	phi2 := p.phi * p.phi
+
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		return ex*(delta*delta-phi2-v-ex)/(2*math.Pow(phi2+v+ex, 2)) - (x-a)/tau2
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi2+v {
+		B = math.Log(delta*delta - phi2 - v)
+	} else {
+		k := 1.0
+		for f(a-k*g.Tau) < 0 {
+			k++
+		}
+		B = a - k*g.Tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > volatilityEpsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
+
+// Updates ratings with the games of a single rating period.
+//
+// All the games of a period are considered simultaneous, i.e. their
+// order does not matter.
+func (g *Glicko2) Update(period []Game) {
+	results := make(map[*glickoPlayer][]glickoResult)
+
+	advantage := g.MasterAdvantage / glickoScale
+
+	for _, game := range period {
+		master := g.player(game.Master)
+		slave := g.player(game.Slave)
+		score := game.MasterScore()
+
+		results[master] = append(results[master], glickoResult{slave, score, advantage})
+		results[slave] = append(results[slave], glickoResult{master, 1 - score, -advantage})
+
+		master.addGame(score)
+		slave.addGame(1 - score)
+	}
+
+	type update struct {
+		mu, phi, sigma float64
+	}
+	updates := make(map[*glickoPlayer]update, len(g.players))
+
+	// Compute everything first, as updates must not affect
+	// other updates within the same period.
+	for _, p := range g.players {
+		games := results[p]
+
+		if len(games) == 0 {
+			updates[p] = update{
+				mu:    p.mu,
+				phi:   math.Sqrt(p.phi*p.phi + p.sigma*p.sigma),
+				sigma: p.sigma,
+			}
+			continue
+		}
+
+		var vInv, deltaSum float64
+		for _, r := range games {
+			gPhi := glickoG(r.opponent.phi)
+			e := glickoE(p.mu+r.advantage, r.opponent.mu, r.opponent.phi)
+
+			vInv += gPhi * gPhi * e * (1 - e)
+			deltaSum += gPhi * (r.score - e)
+		}
+
+		v := 1 / vInv
+		delta := v * deltaSum
+
+		sigma := g.newVolatility(p, delta, v)
+		phiStar := math.Sqrt(p.phi*p.phi + sigma*sigma)
+		phi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+
+		updates[p] = update{
+			mu:    p.mu + phi*phi*deltaSum,
+			phi:   phi,
+			sigma: sigma,
+		}
+	}
+
+	for p, u := range updates {
+		p.mu, p.phi, p.sigma = u.mu, u.phi, u.sigma
+	}
+}
+
+// Returns ratings of all players, best first.
+func (g *Glicko2) Ratings() []Rating {
+	ratings := make([]Rating, 0, len(g.players))
+
+	for _, p := range g.players {
+		r := p.Rating
+		r.Rating = InitialRating + p.mu*glickoScale
+		r.Deviation = p.phi * glickoScale
+		r.Volatility = p.sigma
+		ratings = append(ratings, r)
+	}
+
+	return sortRatings(ratings)
+}
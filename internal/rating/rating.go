@@ -0,0 +1,99 @@
+package rating
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+const (
+	InitialRating float64 = 1500
+
+	// Scale of the logistic curve, in rating points per factor
+	// of 10 in odds. Shared by Elo and Glicko.
+	eloScale float64 = 400
+)
+
+// Outcome of a single match.
+type Game struct {
+	Master string
+	Slave  string
+	Result judge.Result
+}
+
+// Returns the score of the master, i.e. 1 for win, 0.5 for tie and 0 for loss.
+func (g *Game) MasterScore() float64 {
+	switch g.Result {
+	case judge.MasterWon:
+		return 1
+	case judge.SlaveWon:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+type Rating struct {
+	Id     string  `json:"id"`
+	Rating float64 `json:"rating"`
+
+	// Standard deviation of the rating estimate.
+	Deviation float64 `json:"deviation"`
+
+	// Only present for Glicko-2.
+	Volatility float64 `json:"volatility,omitempty"`
+
+	Games  int `json:"games"`
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Ties   int `json:"ties"`
+}
+
+func (r *Rating) addGame(score float64) {
+	r.Games++
+
+	switch score {
+	case 1:
+		r.Wins++
+	case 0:
+		r.Losses++
+	default:
+		r.Ties++
+	}
+}
+
+// Estimates how many rating points being a master is worth, from
+// the average score of masters in the given games.
+//
+// Master chooses the configuration, so it has a structural advantage
+// that would otherwise be attributed to the player's strength.
+func EstimateMasterAdvantage(games []Game) float64 {
+	if len(games) == 0 {
+		return 0
+	}
+
+	var total float64
+	for i := range games {
+		total += games[i].MasterScore()
+	}
+
+	// Add a single tie to both avoid infinities and
+	// not to overreact on a small number of games.
+	score := (total + 0.5) / float64(len(games)+1)
+
+	return eloScale * math.Log10(score/(1-score))
+}
+
+// Expected score of a player with rating `r` against a player with rating `opp`.
+func expectedScore(r, opp float64) float64 {
+	return 1 / (1 + math.Pow(10, (opp-r)/eloScale))
+}
+
+func sortRatings(ratings []Rating) []Rating {
+	slices.SortFunc(ratings, func(a, b Rating) int {
+		return cmp.Or(cmp.Compare(b.Rating, a.Rating), cmp.Compare(a.Id, b.Id))
+	})
+	return ratings
+}
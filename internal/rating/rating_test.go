@@ -0,0 +1,108 @@
+package rating_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/rating"
+)
+
+func findRating(t *testing.T, ratings []rating.Rating, id string) rating.Rating {
+	for _, r := range ratings {
+		if r.Id == id {
+			return r
+		}
+	}
+	require.Failf(t, "no rating", "player %q is not rated", id)
+	return rating.Rating{}
+}
+
+func TestElo_Symmetric(t *testing.T) {
+	elo := rating.NewElo(32, 0)
+
+	elo.Update(rating.Game{Master: "a", Slave: "b", Result: judge.MasterWon})
+
+	ratings := elo.Ratings()
+	require.Len(t, ratings, 2)
+
+	assert.Equal(t, "a", ratings[0].Id)
+	assert.InDelta(t, 1516, ratings[0].Rating, 1e-9)
+	assert.InDelta(t, 1484, ratings[1].Rating, 1e-9)
+	assert.Equal(t, 1, ratings[0].Wins)
+	assert.Equal(t, 1, ratings[1].Losses)
+	assert.Equal(t, ratings[0].Deviation, ratings[1].Deviation)
+}
+
+func TestElo_MasterAdvantage(t *testing.T) {
+	masterWin := rating.Game{Master: "a", Slave: "b", Result: judge.MasterWon}
+	slaveWin := rating.Game{Master: "a", Slave: "b", Result: judge.SlaveWon}
+
+	gain := func(advantage float64, g rating.Game, winner string) float64 {
+		elo := rating.NewElo(32, advantage)
+		elo.Update(g)
+		return findRating(t, elo.Ratings(), winner).Rating - rating.InitialRating
+	}
+
+	// Winning as master is expected, so it's worth less,
+	// and winning as slave is worth more.
+	assert.Less(t, gain(100, masterWin, "a"), gain(0, masterWin, "a"))
+	assert.Greater(t, gain(100, slaveWin, "b"), gain(0, slaveWin, "b"))
+}
+
+func TestEstimateMasterAdvantage(t *testing.T) {
+	assert.Zero(t, rating.EstimateMasterAdvantage(nil))
+
+	balanced := []rating.Game{
+		{Master: "a", Slave: "b", Result: judge.MasterWon},
+		{Master: "b", Slave: "a", Result: judge.SlaveWon},
+		{Master: "a", Slave: "c", Result: judge.Tie},
+	}
+	assert.InDelta(t, 0, rating.EstimateMasterAdvantage(balanced), 1e-9)
+
+	masters := []rating.Game{
+		{Master: "a", Slave: "b", Result: judge.MasterWon},
+		{Master: "b", Slave: "a", Result: judge.MasterWon},
+		{Master: "c", Slave: "a", Result: judge.MasterWon},
+	}
+	assert.Greater(t, rating.EstimateMasterAdvantage(masters), 0.0)
+}
+
+// Example from the Glicko-2 paper by Mark Glickman.
+func TestGlicko2_PaperExample(t *testing.T) {
+	g := rating.NewGlicko2(0.5, 0)
+
+	g.SetRating("player", 1500, 200, 0.06)
+	g.SetRating("a", 1400, 30, 0.06)
+	g.SetRating("b", 1550, 100, 0.06)
+	g.SetRating("c", 1700, 300, 0.06)
+
+	g.Update([]rating.Game{
+		{Master: "player", Slave: "a", Result: judge.MasterWon},
+		{Master: "player", Slave: "b", Result: judge.SlaveWon},
+		{Master: "c", Slave: "player", Result: judge.MasterWon},
+	})
+
+	player := findRating(t, g.Ratings(), "player")
+	assert.InDelta(t, 1464.06, player.Rating, 0.01)
+	assert.InDelta(t, 151.52, player.Deviation, 0.01)
+	assert.InDelta(t, 0.05999, player.Volatility, 0.00001)
+	assert.Equal(t, 3, player.Games)
+	assert.Equal(t, 1, player.Wins)
+	assert.Equal(t, 2, player.Losses)
+}
+
+func TestGlicko2_InactivePlayer(t *testing.T) {
+	g := rating.NewGlicko2(0.5, 0)
+
+	g.SetRating("idle", 1600, 50, 0.06)
+	g.Update([]rating.Game{
+		{Master: "a", Slave: "b", Result: judge.Tie},
+	})
+
+	idle := findRating(t, g.Ratings(), "idle")
+	assert.InDelta(t, 1600, idle.Rating, 1e-9)
+	assert.Greater(t, idle.Deviation, 50.0)
+}
@@ -0,0 +1,34 @@
+package replay
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field/render"
+)
+
+func (s *State) board(owner game.Role) *render.Board {
+	shots := make([]render.Shot, len(s.Shots[owner]))
+	for i, shot := range s.Shots[owner] {
+		shots[i] = render.Shot{X: shot.X, Y: shot.Y, Result: shot.Result}
+	}
+
+	return render.New(s.Configuration, loadField(s.Configuration, s.Ships[owner]), shots)
+}
+
+// Writes field of the given player as an ASCII grid, see `render.Board.WriteASCII`.
+func (s *State) WriteASCII(w io.Writer, owner game.Role) error {
+	return s.board(owner).WriteASCII(w)
+}
+
+// Writes both fields side by side as an SVG image.
+func (s *State) WriteSVG(w io.Writer) error {
+	master := s.board(game.RoleMaster)
+	master.Title = fmt.Sprintf("master (turn %d)", s.Turn)
+
+	slave := s.board(game.RoleSlave)
+	slave.Title = "slave"
+
+	return render.WriteSVG(w, master, slave)
+}
@@ -0,0 +1,141 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+var (
+	ErrNoConfiguration error = errors.New("round has no configuration")
+)
+
+type Shot struct {
+	Shooter game.Role `json:"shooter"`
+	X       int64     `json:"x"`
+	Y       int64     `json:"y"`
+
+	// Result according to the victim's dumped field.
+	Result field.ShootResult `json:"result"`
+
+	// Result the victim has reported, as is.
+	Reported string `json:"reported"`
+}
+
+// Game state after the given number of shots.
+type State struct {
+	Turn          int
+	Configuration field.Configuration
+
+	// Ships and shots received, by the role of the field owner.
+	// Ships are nil if the owner's field is unknown.
+	Ships [2][]field.Ship
+	Shots [2][]Shot
+}
+
+type Replay struct {
+	Configuration field.Configuration
+
+	// Ships of each player, by role. Nil if the field wasn't dumped.
+	Ships [2][]field.Ship
+
+	// All shots made in the round, in order.
+	Shots []Shot
+}
+
+func loadField(conf field.Configuration, ships []field.Ship) field.Field {
+	if ships == nil {
+		return nil
+	}
+
+	f := field.NewShipField(uint32(len(ships)))
+	if f.Load(conf, slices.Values(ships)) != nil {
+		return nil
+	}
+
+	return f
+}
+
+// Rebuilds the round from its transcript by re-applying all
+// the recorded shots to the dumped fields. If the transcript is
+// truncated, so is the replay.
+func New(round *judge.RoundTranscript) (*Replay, error) {
+	if round.Configuration == nil {
+		return nil, ErrNoConfiguration
+	}
+
+	r := &Replay{
+		Configuration: *round.Configuration,
+	}
+
+	for _, dump := range round.Fields {
+		if dump.Error == "" {
+			r.Ships[dump.Role] = dump.Ships
+		}
+	}
+
+	fields := [2]field.Field{
+		loadField(r.Configuration, r.Ships[game.RoleMaster]),
+		loadField(r.Configuration, r.Ships[game.RoleSlave]),
+	}
+
+	for _, exchange := range round.Exchanges {
+		// Shots are taken from the victim side, as that's
+		// where both coordinates and the result are.
+		if exchange.Error != "" || !strings.HasPrefix(exchange.Command, "shot ") {
+			continue
+		}
+
+		var x, y int64
+		if n, err := fmt.Sscanf(exchange.Command, "shot %d %d", &x, &y); err != nil || n != 2 {
+			continue
+		}
+
+		shot := Shot{
+			Shooter:  exchange.Role.Other(),
+			X:        x,
+			Y:        y,
+			Reported: exchange.Response,
+		}
+
+		if victimField := fields[exchange.Role]; victimField != nil {
+			shot.Result = victimField.Shoot(x, y)
+		} else if err := shot.Result.FromString(exchange.Response); err != nil {
+			shot.Result = field.Miss
+		}
+
+		r.Shots = append(r.Shots, shot)
+	}
+
+	return r, nil
+}
+
+// Returns the number of turns, i.e. shots, in the round.
+func (r *Replay) Turns() int {
+	return len(r.Shots)
+}
+
+// Returns the state after the first `turn` shots.
+//
+// Turn is clamped to the [0, Turns()] range.
+func (r *Replay) State(turn int) *State {
+	turn = min(max(turn, 0), r.Turns())
+
+	s := &State{
+		Turn:          turn,
+		Configuration: r.Configuration,
+		Ships:         r.Ships,
+	}
+
+	for _, shot := range r.Shots[:turn] {
+		victim := shot.Shooter.Other()
+		s.Shots[victim] = append(s.Shots[victim], shot)
+	}
+
+	return s
+}
@@ -0,0 +1,124 @@
+package replay_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/replay"
+)
+
+// Master:    Slave:
+// A A .      . . B
+// . . .      . . B
+// . . B      A . .
+func newRound() *judge.RoundTranscript {
+	conf := field.Configuration{
+		W:     3,
+		H:     3,
+		Sizes: []int64{1, 1, 0, 0},
+	}
+
+	exchange := func(role game.Role, cmd, resp string) judge.Exchange {
+		return judge.Exchange{Role: role, Command: cmd, Response: resp}
+	}
+
+	return &judge.RoundTranscript{
+		Configuration: &conf,
+		Fields: []judge.FieldDump{
+			{Role: game.RoleMaster, Ships: []field.Ship{
+				{X: 0, Y: 0, Size: 2, IsVert: false},
+				{X: 2, Y: 2, Size: 1, IsVert: false},
+			}},
+			{Role: game.RoleSlave, Ships: []field.Ship{
+				{X: 2, Y: 0, Size: 2, IsVert: true},
+				{X: 0, Y: 2, Size: 1, IsVert: false},
+			}},
+		},
+		Exchanges: []judge.Exchange{
+			exchange(game.RoleSlave, "shot", "0 0"),
+			exchange(game.RoleMaster, "shot 0 0", "hit"),
+			exchange(game.RoleSlave, "set result hit", "ok"),
+			exchange(game.RoleSlave, "shot", "1 1"),
+			exchange(game.RoleMaster, "shot 1 1", "miss"),
+			exchange(game.RoleSlave, "set result miss", "ok"),
+			exchange(game.RoleMaster, "shot", "0 2"),
+			exchange(game.RoleSlave, "shot 0 2", "kill"),
+			exchange(game.RoleMaster, "set result kill", "ok"),
+			exchange(game.RoleMaster, "shot", "2 0"),
+			// Slave lies about the result.
+			exchange(game.RoleSlave, "shot 2 0", "miss"),
+		},
+	}
+}
+
+func renderASCII(t *testing.T, s *replay.State, role game.Role) string {
+	var sb strings.Builder
+	require.NoError(t, s.WriteASCII(&sb, role))
+	return sb.String()
+}
+
+func TestReplay_Shots(t *testing.T) {
+	r, err := replay.New(newRound())
+	require.NoError(t, err)
+
+	assert.Equal(t, []replay.Shot{
+		{Shooter: game.RoleSlave, X: 0, Y: 0, Result: field.Hit, Reported: "hit"},
+		{Shooter: game.RoleSlave, X: 1, Y: 1, Result: field.Miss, Reported: "miss"},
+		{Shooter: game.RoleMaster, X: 0, Y: 2, Result: field.Kill, Reported: "kill"},
+		{Shooter: game.RoleMaster, X: 2, Y: 0, Result: field.Hit, Reported: "miss"},
+	}, r.Shots)
+}
+
+func TestReplay_ASCII(t *testing.T) {
+	r, err := replay.New(newRound())
+	require.NoError(t, err)
+
+	initial := r.State(0)
+	assert.Equal(t, "2 2 .\n. . .\n. . 1\n", renderASCII(t, initial, game.RoleMaster))
+	assert.Equal(t, ". . 2\n. . 2\n1 . .\n", renderASCII(t, initial, game.RoleSlave))
+
+	middle := r.State(2)
+	assert.Equal(t, "x 2 .\n. o .\n. . 1\n", renderASCII(t, middle, game.RoleMaster))
+	assert.Equal(t, ". . 2\n. . 2\n1 . .\n", renderASCII(t, middle, game.RoleSlave))
+
+	final := r.State(100)
+	assert.Equal(t, r.Turns(), final.Turn)
+	assert.Equal(t, "x 2 .\n. o .\n. . 1\n", renderASCII(t, final, game.RoleMaster))
+	assert.Equal(t, ". . x\n. . 2\nX . .\n", renderASCII(t, final, game.RoleSlave))
+}
+
+func TestReplay_UnknownField(t *testing.T) {
+	round := newRound()
+	round.Fields[1].Ships = nil
+	round.Fields[1].Error = "did not dump field"
+
+	r, err := replay.New(round)
+	require.NoError(t, err)
+
+	// Without the field, reported results are all we have.
+	assert.Equal(t, ". . o\n. . .\nX . .\n", renderASCII(t, r.State(r.Turns()), game.RoleSlave))
+}
+
+func TestReplay_SVG(t *testing.T) {
+	r, err := replay.New(newRound())
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, r.State(r.Turns()).WriteSVG(&sb))
+
+	svg := sb.String()
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.True(t, strings.HasSuffix(svg, "</svg>\n"))
+	assert.Contains(t, svg, "master (turn 4)")
+}
+
+func TestReplay_NoConfiguration(t *testing.T) {
+	_, err := replay.New(&judge.RoundTranscript{})
+	assert.ErrorIs(t, err, replay.ErrNoConfiguration)
+}
@@ -0,0 +1,260 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+// Append-only JSON lines file with all its records kept in memory.
+type table[T any] struct {
+	file    *os.File
+	id      func(*T) string
+	records []T
+	index   map[string]int
+}
+
+func openTable[T any](path string, id func(*T) string) (*table[T], error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &table[T]{
+		file:  file,
+		id:    id,
+		index: make(map[string]int),
+	}
+
+	if err := t.load(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+
+	return t, nil
+}
+
+func (t *table[T]) load() error {
+	reader := bufio.NewReader(t.file)
+
+	var offset int64
+	for lineNo := 1; ; lineNo++ {
+		line, err := reader.ReadBytes('\n')
+
+		if errors.Is(err, io.EOF) {
+			// Line without a newline is a write that was cut short.
+			// Drop it, so that new records are not glued onto it.
+			if len(line) > 0 {
+				if err := t.file.Truncate(offset); err != nil {
+					return err
+				}
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		offset += int64(len(line))
+
+		var record T
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		t.index[t.id(&record)] = len(t.records)
+		t.records = append(t.records, record)
+	}
+
+	_, err := t.file.Seek(offset, io.SeekStart)
+	return err
+}
+
+func (t *table[T]) add(record T) error {
+	if _, exists := t.index[t.id(&record)]; exists {
+		return ErrExists
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if _, err := t.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	t.index[t.id(&record)] = len(t.records)
+	t.records = append(t.records, record)
+
+	return nil
+}
+
+func (t *table[T]) get(id string) (T, error) {
+	if i, ok := t.index[id]; ok {
+		return t.records[i], nil
+	}
+
+	var record T
+	return record, ErrNotFound
+}
+
+func (t *table[T]) filter(predicate func(*T) bool) []T {
+	var records []T
+
+	for i := range t.records {
+		if predicate(&t.records[i]) {
+			records = append(records, t.records[i])
+		}
+	}
+
+	return records
+}
+
+// Store kept in a directory as JSON lines files, one per record type.
+//
+// All records are loaded into memory when the store is opened.
+//
+// FileStore is thread safe.
+type FileStore struct {
+	mu             sync.RWMutex
+	builds         *table[BuildRecord]
+	matches        *table[MatchRecord]
+	transcriptsDir string
+}
+
+func OpenFileStore(dir string) (*FileStore, error) {
+	transcriptsDir := filepath.Join(dir, "transcripts")
+	if err := os.MkdirAll(transcriptsDir, 0755); err != nil {
+		return nil, err
+	}
+
+	builds, err := openTable(filepath.Join(dir, "builds.jsonl"), func(r *BuildRecord) string {
+		return r.Id
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := openTable(filepath.Join(dir, "matches.jsonl"), func(r *MatchRecord) string {
+		return r.Id
+	})
+	if err != nil {
+		builds.file.Close()
+		return nil, err
+	}
+
+	return &FileStore{
+		builds:         builds,
+		matches:        matches,
+		transcriptsDir: transcriptsDir,
+	}, nil
+}
+
+func (s *FileStore) AddBuild(r BuildRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.builds.add(r)
+}
+
+func (s *FileStore) Build(id string) (BuildRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.builds.get(id)
+}
+
+func (s *FileStore) Builds(filter BuildFilter) ([]BuildRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.builds.filter(filter.Matches), nil
+}
+
+func (s *FileStore) AddMatch(r MatchRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.matches.add(r)
+}
+
+func (s *FileStore) Match(id string) (MatchRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.matches.get(id)
+}
+
+func (s *FileStore) Matches(filter MatchFilter) ([]MatchRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.matches.filter(filter.Matches), nil
+}
+
+func (s *FileStore) transcriptPath(matchId string) (string, bool) {
+	if matchId == "" || matchId == "." || matchId == ".." || strings.ContainsAny(matchId, `/\`) {
+		return "", false
+	}
+
+	return filepath.Join(s.transcriptsDir, matchId+".json"), true
+}
+
+func (s *FileStore) AddTranscript(matchId string, t *judge.Transcript) error {
+	path, ok := s.transcriptPath(matchId)
+	if !ok {
+		return fmt.Errorf("invalid match id: %q", matchId)
+	}
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that a crash can't leave
+	// a partially written transcript behind.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (s *FileStore) Transcript(matchId string) (*judge.Transcript, error) {
+	path, ok := s.transcriptPath(matchId)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var t judge.Transcript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return errors.Join(s.builds.file.Close(), s.matches.file.Close())
+}
@@ -0,0 +1,144 @@
+package store_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/store"
+)
+
+func TestFileStore_Builds(t *testing.T) {
+	s, err := store.OpenFileStore(t.TempDir())
+	require.NoError(t, err)
+	defer s.Close()
+
+	ok := store.BuildRecord{Id: "1", Repo: "org/a", Ref: "main", ImageId: "sha256:a", Logs: "built", Duration: time.Minute}
+	bad := store.BuildRecord{Id: "2", Repo: "org/b", Ref: "main", Error: "unknown", Details: "oops"}
+
+	require.NoError(t, s.AddBuild(ok))
+	require.NoError(t, s.AddBuild(bad))
+	assert.ErrorIs(t, s.AddBuild(ok), store.ErrExists)
+
+	got, err := s.Build("1")
+	require.NoError(t, err)
+	assert.Equal(t, ok, got)
+
+	_, err = s.Build("3")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	all, err := s.Builds(store.BuildFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []store.BuildRecord{ok, bad}, all)
+
+	filtered, err := s.Builds(store.BuildFilter{Repo: "org/b"})
+	require.NoError(t, err)
+	assert.Equal(t, []store.BuildRecord{bad}, filtered)
+}
+
+func TestFileStore_Matches(t *testing.T) {
+	s, err := store.OpenFileStore(t.TempDir())
+	require.NoError(t, err)
+	defer s.Close()
+
+	ab := store.MatchRecord{Id: "1", MasterImageId: "a", SlaveImageId: "b", Verdict: judge.Verdict{Winner: judge.MasterWon}}
+	bc := store.MatchRecord{Id: "2", MasterImageId: "b", SlaveImageId: "c", Verdict: judge.Verdict{Winner: judge.Tie, Reason: judge.MemoryLimit}}
+
+	require.NoError(t, s.AddMatch(ab))
+	require.NoError(t, s.AddMatch(bc))
+
+	withB, err := s.Matches(store.MatchFilter{ImageId: "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []store.MatchRecord{ab, bc}, withB)
+
+	masterB, err := s.Matches(store.MatchFilter{MasterImageId: "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []store.MatchRecord{bc}, masterB)
+}
+
+func TestFileStore_Reopen(t *testing.T) {
+	dir := t.TempDir()
+
+	match := store.MatchRecord{
+		Id:            "1",
+		MasterImageId: "a",
+		SlaveImageId:  "b",
+		Verdict: judge.Verdict{
+			Winner:  judge.SlaveWon,
+			Reason:  judge.Timeout,
+			Details: "master timeout",
+		},
+		StartedAt:  time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC),
+		FinishedAt: time.Date(2024, 12, 1, 12, 5, 0, 0, time.UTC),
+	}
+
+	{
+		s, err := store.OpenFileStore(dir)
+		require.NoError(t, err)
+		require.NoError(t, s.AddMatch(match))
+		require.NoError(t, s.Close())
+	}
+
+	// Simulate a crash in the middle of a write.
+	f, err := os.OpenFile(filepath.Join(dir, "matches.jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"2","master_ima`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	{
+		s, err := store.OpenFileStore(dir)
+		require.NoError(t, err)
+
+		got, err := s.Match("1")
+		require.NoError(t, err)
+		assert.Equal(t, match, got)
+
+		require.NoError(t, s.AddMatch(store.MatchRecord{Id: "2", MasterImageId: "b", SlaveImageId: "a"}))
+		require.NoError(t, s.Close())
+	}
+
+	s, err := store.OpenFileStore(dir)
+	require.NoError(t, err)
+	defer s.Close()
+
+	all, err := s.Matches(store.MatchFilter{})
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestFileStore_Transcripts(t *testing.T) {
+	s, err := store.OpenFileStore(t.TempDir())
+	require.NoError(t, err)
+	defer s.Close()
+
+	transcript := &judge.Transcript{
+		Rounds: []*judge.RoundTranscript{
+			{
+				Exchanges: []judge.Exchange{
+					{Role: game.RoleMaster, Command: "create master", Response: "ok", Time: time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC)},
+					{Role: game.RoleSlave, Command: "create slave", Error: "player terminated due to runtime error", Time: time.Date(2024, 12, 1, 12, 0, 1, 0, time.UTC)},
+				},
+				Fields: []judge.FieldDump{},
+			},
+		},
+	}
+
+	require.NoError(t, s.AddTranscript("1", transcript))
+
+	got, err := s.Transcript("1")
+	require.NoError(t, err)
+	assert.Equal(t, transcript, got)
+
+	_, err = s.Transcript("2")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	_, err = s.Transcript("../1")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
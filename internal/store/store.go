@@ -0,0 +1,100 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+var (
+	ErrNotFound error = errors.New("record not found")
+	ErrExists   error = errors.New("record already exists")
+)
+
+type BuildRecord struct {
+	Id string `json:"id"`
+
+	// Source the submission was built from.
+	Repo string `json:"repo,omitempty"`
+	Ref  string `json:"ref,omitempty"`
+	Src  string `json:"src,omitempty"`
+
+	ImageId string `json:"image_id,omitempty"`
+	Logs    string `json:"logs"`
+	Error   string `json:"error,omitempty"`
+	Details string `json:"details,omitempty"`
+
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+}
+
+type MatchRecord struct {
+	Id            string        `json:"id"`
+	MasterImageId string        `json:"master_image_id"`
+	SlaveImageId  string        `json:"slave_image_id"`
+	Verdict       judge.Verdict `json:"verdict"`
+
+	// Name of the rule set the match was judged by.
+	// Empty for matches made before rules were selectable.
+	Rules string `json:"rules,omitempty"`
+
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Empty fields match any value.
+type BuildFilter struct {
+	Repo    string
+	ImageId string
+}
+
+func (f *BuildFilter) Matches(r *BuildRecord) bool {
+	return (f.Repo == "" || f.Repo == r.Repo) &&
+		(f.ImageId == "" || f.ImageId == r.ImageId)
+}
+
+// Empty fields match any value.
+type MatchFilter struct {
+	MasterImageId string
+	SlaveImageId  string
+
+	// Matches if either of the players has the given image.
+	ImageId string
+}
+
+func (f *MatchFilter) Matches(r *MatchRecord) bool {
+	return (f.MasterImageId == "" || f.MasterImageId == r.MasterImageId) &&
+		(f.SlaveImageId == "" || f.SlaveImageId == r.SlaveImageId) &&
+		(f.ImageId == "" || f.ImageId == r.MasterImageId || f.ImageId == r.SlaveImageId)
+}
+
+// Storage of finished builds and matches.
+//
+// Records are immutable: once added, they are never changed or removed.
+// Listing methods return records in the order they were added.
+type Store interface {
+	// Returns `ErrExists` if build with the same id was already added.
+	AddBuild(BuildRecord) error
+
+	// Returns `ErrNotFound` if there is no such build.
+	Build(id string) (BuildRecord, error)
+
+	Builds(BuildFilter) ([]BuildRecord, error)
+
+	// Returns `ErrExists` if match with the same id was already added.
+	AddMatch(MatchRecord) error
+
+	// Returns `ErrNotFound` if there is no such match.
+	Match(id string) (MatchRecord, error)
+
+	Matches(MatchFilter) ([]MatchRecord, error)
+
+	// Transcripts are kept apart from matches, as they may be huge.
+	AddTranscript(matchId string, t *judge.Transcript) error
+
+	// Returns `ErrNotFound` if there is no transcript for the match.
+	Transcript(matchId string) (*judge.Transcript, error)
+
+	Close() error
+}
@@ -0,0 +1,135 @@
+package tournament
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+// Ordered pair of participants, i.e. a single match.
+type Pair struct {
+	Master string `json:"master_image_id"`
+	Slave  string `json:"slave_image_id"`
+
+	// Swiss round the match is played in, starting from 1, as the same
+	// participants may meet again. Zero for round robin.
+	Round int `json:"round,omitempty"`
+}
+
+type MatchResult struct {
+	judge.Verdict
+	Pair
+}
+
+// Append-only log of match results, stored as JSON lines.
+//
+// The format is the same as the one `match_results.json` had, so
+// results of runs made with the old scripts can be resumed too.
+//
+// ResultLog is thread safe.
+type ResultLog struct {
+	mu        sync.Mutex
+	file      *os.File
+	completed map[Pair]MatchResult
+}
+
+// Opens (or creates) result log at the given path and loads
+// pairs that were already played.
+//
+// Malformed lines, e.g. the last line written by a crashed run,
+// are skipped.
+func OpenResultLog(path string) (*ResultLog, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &ResultLog{
+		file:      file,
+		completed: make(map[Pair]MatchResult),
+	}
+
+	if err := l.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *ResultLog) load() error {
+	reader := bufio.NewReader(l.file)
+
+	var last byte = '\n'
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			last = line[len(line)-1]
+
+			var result MatchResult
+			if json.Unmarshal(line, &result) == nil && result.Master != "" && result.Slave != "" {
+				l.completed[result.Pair] = result
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Don't glue new results onto a truncated line.
+	if last != '\n' {
+		if _, err := l.file.Write([]byte{'\n'}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *ResultLog) Has(pair Pair) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, ok := l.completed[pair]
+	return ok
+}
+
+// Returns the latest result of the given pair, if it was played.
+func (l *ResultLog) Get(pair Pair) (MatchResult, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result, ok := l.completed[pair]
+	return result, ok
+}
+
+func (l *ResultLog) Append(result MatchResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.file.Write(data); err != nil {
+		return err
+	}
+
+	l.completed[result.Pair] = result
+
+	return nil
+}
+
+func (l *ResultLog) Close() error {
+	return l.file.Close()
+}
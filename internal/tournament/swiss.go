@@ -0,0 +1,349 @@
+package tournament
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+// Upper bound on the number of attempts the pairing search makes before
+// giving up on avoiding repeat pairings.
+const maxPairingSteps = 1_000_000
+
+// Points awarded for a bye, same as for a win.
+const byeScore float64 = 1
+
+// A single round of a Swiss tournament.
+type Round struct {
+	Pairs []Pair
+
+	// Participant that sits this round out, if the number of
+	// participants is odd. Empty otherwise.
+	Bye string
+
+	// Set if the search for pairs without repeats has given up after
+	// `maxPairingSteps`, so repeats may have been made needlessly.
+	GaveUp bool
+}
+
+// Final (or intermediate) standing of a participant.
+type Standing struct {
+	Id    string  `json:"id"`
+	Score float64 `json:"score"`
+
+	// Sum of scores of all opponents.
+	Buchholz float64 `json:"buchholz"`
+
+	// Sum of scores of defeated opponents plus half
+	// the sum of scores of tied opponents.
+	SonnebornBerger float64 `json:"sonneborn_berger"`
+
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Ties   int `json:"ties"`
+	Byes   int `json:"byes"`
+}
+
+type swissGame struct {
+	opponent *swissPlayer
+	score    float64
+}
+
+type swissPlayer struct {
+	id string
+
+	// Position in the initial ordering, used to break ties when pairing.
+	seed int
+
+	score float64
+	byes  int
+	games []swissGame
+
+	// Number of games played as master minus number of games played as slave.
+	balance  int
+	lastRole int
+
+	opponents map[*swissPlayer]int
+}
+
+func (p *swissPlayer) addGame(opponent *swissPlayer, score float64, role int) {
+	p.games = append(p.games, swissGame{opponent, score})
+	p.opponents[opponent]++
+	p.score += score
+	p.balance += role
+	p.lastRole = role
+}
+
+// Swiss-system pairing.
+//
+// Every round participants with similar scores are paired with each
+// other, avoiding repeat pairings while possible. Master role goes to
+// the participant that was master less often, so that roles alternate.
+//
+// Pairing is deterministic, i.e. the same results always produce the
+// same rounds. This is what makes tournaments resumable.
+type Swiss struct {
+	players []*swissPlayer
+	byId    map[string]*swissPlayer
+}
+
+// Creates Swiss pairing for the given participants. Their order is used
+// as the initial seeding, i.e. earlier participants are considered stronger
+// until scores say otherwise.
+func NewSwiss(ids []string) *Swiss {
+	s := &Swiss{
+		byId: make(map[string]*swissPlayer, len(ids)),
+	}
+
+	for i, id := range ids {
+		p := &swissPlayer{
+			id:        id,
+			seed:      i,
+			opponents: make(map[*swissPlayer]int),
+		}
+		s.players = append(s.players, p)
+		s.byId[id] = p
+	}
+
+	return s
+}
+
+// Returns participants ordered by score, then by seed.
+func (s *Swiss) ranked() []*swissPlayer {
+	ranked := slices.Clone(s.players)
+	slices.SortFunc(ranked, func(a, b *swissPlayer) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), cmp.Compare(a.seed, b.seed))
+	})
+	return ranked
+}
+
+type pairing struct {
+	steps        int
+	allowRepeats bool
+}
+
+// Pairs participants in order, each one with the closest possible
+// unpaired participant below it, backtracking when stuck.
+func (p *pairing) match(players []*swissPlayer) ([][2]*swissPlayer, bool) {
+	if len(players) == 0 {
+		return nil, true
+	}
+
+	a := players[0]
+	for i := 1; i < len(players); i++ {
+		p.steps++
+		if p.steps > maxPairingSteps {
+			return nil, false
+		}
+
+		b := players[i]
+		if !p.allowRepeats && a.opponents[b] > 0 {
+			continue
+		}
+
+		rest := make([]*swissPlayer, 0, len(players)-2)
+		rest = append(rest, players[1:i]...)
+		rest = append(rest, players[i+1:]...)
+
+		if matched, ok := p.match(rest); ok {
+			return append(matched, [2]*swissPlayer{a, b}), true
+		}
+	}
+
+	return nil, false
+}
+
+// Decides who of the two is going to be the master.
+func orient(a, b *swissPlayer) Pair {
+	swap := cmp.Or(
+		cmp.Compare(a.balance, b.balance),
+		cmp.Compare(a.lastRole, b.lastRole),
+	) > 0
+
+	if swap {
+		a, b = b, a
+	}
+
+	return Pair{Master: a.id, Slave: b.id}
+}
+
+// Returns pairs of the next round. It doesn't change the state, so
+// results (and the bye) of the round must be recorded afterwards.
+// Pairs don't have their `Round` set.
+//
+// Repeat pairings are only made if there is no other way to pair
+// everyone, e.g. when there are more rounds than opponents.
+func (s *Swiss) Pair() Round {
+	ranked := s.ranked()
+
+	var round Round
+
+	if len(ranked)%2 == 1 {
+		// Lowest ranked participant among the ones with the fewest byes.
+		bye := len(ranked) - 1
+		for i := len(ranked) - 1; i >= 0; i-- {
+			if ranked[i].byes < ranked[bye].byes {
+				bye = i
+			}
+		}
+
+		round.Bye = ranked[bye].id
+		ranked = slices.Delete(ranked, bye, bye+1)
+	}
+
+	p := pairing{}
+	matched, ok := p.match(ranked)
+	if !ok {
+		round.GaveUp = p.steps > maxPairingSteps
+
+		p = pairing{allowRepeats: true}
+		matched, _ = p.match(ranked)
+	}
+
+	// Matches are collected bottom up.
+	slices.Reverse(matched)
+
+	for _, m := range matched {
+		round.Pairs = append(round.Pairs, orient(m[0], m[1]))
+	}
+
+	return round
+}
+
+func (s *Swiss) player(id string) (*swissPlayer, error) {
+	p, ok := s.byId[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown participant: %q", id)
+	}
+	return p, nil
+}
+
+// Records the result of a match.
+func (s *Swiss) Record(result MatchResult) error {
+	master, err := s.player(result.Master)
+	if err != nil {
+		return err
+	}
+
+	slave, err := s.player(result.Slave)
+	if err != nil {
+		return err
+	}
+
+	var score float64
+	switch result.Winner {
+	case judge.MasterWon:
+		score = 1
+	case judge.SlaveWon:
+		score = 0
+	default:
+		score = 0.5
+	}
+
+	master.addGame(slave, score, 1)
+	slave.addGame(master, 1-score, -1)
+
+	return nil
+}
+
+// Records that the participant sat the round out.
+func (s *Swiss) RecordBye(id string) error {
+	p, err := s.player(id)
+	if err != nil {
+		return err
+	}
+
+	p.byes++
+	p.score += byeScore
+
+	return nil
+}
+
+// Returns current standings, best first. Ties in score are broken
+// by Buchholz, then by Sonneborn-Berger, then by number of wins.
+func (s *Swiss) Standings() []Standing {
+	standings := make([]Standing, 0, len(s.players))
+
+	for _, p := range s.players {
+		st := Standing{
+			Id:    p.id,
+			Score: p.score,
+			Byes:  p.byes,
+		}
+
+		for _, g := range p.games {
+			st.Buchholz += g.opponent.score
+			st.SonnebornBerger += g.score * g.opponent.score
+
+			switch g.score {
+			case 1:
+				st.Wins++
+			case 0:
+				st.Losses++
+			default:
+				st.Ties++
+			}
+		}
+
+		standings = append(standings, st)
+	}
+
+	slices.SortStableFunc(standings, func(a, b Standing) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(b.Buchholz, a.Buchholz),
+			cmp.Compare(b.SonnebornBerger, a.SonnebornBerger),
+			cmp.Compare(b.Wins, a.Wins),
+		)
+	})
+
+	return standings
+}
+
+// Plays the given number of Swiss rounds between participants and
+// returns the final standings.
+//
+// Same as `Run`, results are saved to the result log as soon as they are
+// known, and the tournament can be resumed. Since pairing is deterministic,
+// already played rounds are reconstructed from the log, provided that
+// participants are given in the same order.
+func (t *Tournament) RunSwiss(ctx context.Context, ids []string, rounds int) ([]Standing, error) {
+	swiss := NewSwiss(ids)
+
+	for i := range rounds {
+		round := swiss.Pair()
+		for j := range round.Pairs {
+			round.Pairs[j].Round = i + 1
+		}
+
+		if t.OnRound != nil {
+			t.OnRound(round)
+		}
+
+		if err := t.Run(ctx, round.Pairs); err != nil {
+			return nil, err
+		}
+
+		for _, pair := range round.Pairs {
+			result, ok := t.Results.Get(pair)
+			if !ok {
+				return nil, fmt.Errorf("no result for %s vs %s", pair.Master, pair.Slave)
+			}
+
+			if err := swiss.Record(result); err != nil {
+				return nil, err
+			}
+		}
+
+		if round.Bye != "" {
+			if err := swiss.RecordBye(round.Bye); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return swiss.Standings(), nil
+}
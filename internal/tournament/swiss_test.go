@@ -0,0 +1,191 @@
+package tournament_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/tournament"
+)
+
+func unordered(p tournament.Pair) [2]string {
+	if p.Master < p.Slave {
+		return [2]string{p.Master, p.Slave}
+	}
+	return [2]string{p.Slave, p.Master}
+}
+
+// Plays rounds where the participant that comes first alphabetically always wins.
+func playSwiss(t *testing.T, swiss *tournament.Swiss, rounds int) []tournament.Round {
+	var played []tournament.Round
+
+	for range rounds {
+		round := swiss.Pair()
+		played = append(played, round)
+
+		for _, pair := range round.Pairs {
+			winner := judge.SlaveWon
+			if pair.Master < pair.Slave {
+				winner = judge.MasterWon
+			}
+
+			require.NoError(t, swiss.Record(tournament.MatchResult{
+				Verdict: judge.Verdict{Winner: winner},
+				Pair:    pair,
+			}))
+		}
+
+		if round.Bye != "" {
+			require.NoError(t, swiss.RecordBye(round.Bye))
+		}
+	}
+
+	return played
+}
+
+func TestSwiss_NoRepeats(t *testing.T) {
+	swiss := tournament.NewSwiss([]string{"a", "b", "c", "d", "e", "f"})
+
+	seen := make(map[[2]string]bool)
+	for _, round := range playSwiss(t, swiss, 5) {
+		assert.Len(t, round.Pairs, 3)
+		assert.Empty(t, round.Bye)
+
+		for _, pair := range round.Pairs {
+			key := unordered(pair)
+			assert.False(t, seen[key], "repeat pairing %v", key)
+			seen[key] = true
+		}
+	}
+
+	// More rounds than opponents, so repeats are inevitable.
+	assert.Len(t, swiss.Pair().Pairs, 3)
+}
+
+func TestSwiss_SimilarScores(t *testing.T) {
+	swiss := tournament.NewSwiss([]string{"a", "b", "c", "d"})
+
+	first := swiss.Pair()
+	assert.Equal(t, []tournament.Pair{
+		{Master: "a", Slave: "b"},
+		{Master: "c", Slave: "d"},
+	}, first.Pairs)
+
+	for _, pair := range first.Pairs {
+		require.NoError(t, swiss.Record(tournament.MatchResult{
+			Verdict: judge.Verdict{Winner: judge.MasterWon},
+			Pair:    pair,
+		}))
+	}
+
+	// Winners play winners and losers play losers. Since both
+	// losers were slaves, the higher ranked one is now master.
+	assert.Equal(t, []tournament.Pair{
+		{Master: "a", Slave: "c"},
+		{Master: "b", Slave: "d"},
+	}, swiss.Pair().Pairs)
+}
+
+func TestSwiss_Byes(t *testing.T) {
+	swiss := tournament.NewSwiss([]string{"a", "b", "c"})
+
+	byes := make(map[string]int)
+	for _, round := range playSwiss(t, swiss, 3) {
+		assert.Len(t, round.Pairs, 1)
+		byes[round.Bye]++
+	}
+
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "c": 1}, byes)
+}
+
+func TestSwiss_Standings(t *testing.T) {
+	swiss := tournament.NewSwiss([]string{"a", "b", "c", "d"})
+	playSwiss(t, swiss, 3)
+
+	standings := swiss.Standings()
+	require.Len(t, standings, 4)
+
+	ids := make([]string, 0, len(standings))
+	for _, st := range standings {
+		ids = append(ids, st.Id)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, ids)
+
+	assert.Equal(t, 3.0, standings[0].Score)
+	assert.Equal(t, 3, standings[0].Wins)
+	assert.Equal(t, 3.0, standings[0].Buchholz)
+	assert.Equal(t, 3.0, standings[0].SonnebornBerger)
+
+	assert.Equal(t, 0.0, standings[3].Score)
+	assert.Equal(t, 3, standings[3].Losses)
+	assert.Equal(t, 0.0, standings[3].SonnebornBerger)
+}
+
+func TestSwiss_UnknownParticipant(t *testing.T) {
+	swiss := tournament.NewSwiss([]string{"a"})
+
+	err := swiss.Record(tournament.MatchResult{Pair: tournament.Pair{Master: "a", Slave: "b"}})
+	assert.Error(t, err)
+	assert.Error(t, swiss.RecordBye("b"))
+}
+
+func TestTournament_RunSwiss(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	ids := []string{"a", "b", "c", "d", "e"}
+
+	var played atomic.Int64
+	tour := newTournament(t, path)
+	tour.OnResult = func(tournament.MatchResult) {
+		played.Add(1)
+	}
+
+	standings, err := tour.RunSwiss(context.Background(), ids, 3)
+	require.NoError(t, err)
+	require.Len(t, standings, len(ids))
+	assert.EqualValues(t, 6, played.Load())
+
+	// Resumed tournament reuses saved results, and ends up the same.
+	played.Store(0)
+	reopened := newTournament(t, path)
+	reopened.OnResult = tour.OnResult
+
+	resumed, err := reopened.RunSwiss(context.Background(), ids, 3)
+	require.NoError(t, err)
+	assert.Zero(t, played.Load())
+	assert.Equal(t, standings, resumed)
+}
+
+// Two participants meet every round, sometimes in the same roles,
+// but each round is still played and counted.
+func TestTournament_RunSwissRepeats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+
+	var played atomic.Int64
+	tour := newTournament(t, path)
+	tour.OnResult = func(tournament.MatchResult) {
+		played.Add(1)
+	}
+
+	standings, err := tour.RunSwiss(context.Background(), []string{"a", "b"}, 3)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, played.Load())
+
+	for _, st := range standings {
+		assert.Equal(t, 3, st.Wins+st.Losses+st.Ties)
+	}
+
+	// Resumed tournament finds results of all rounds.
+	played.Store(0)
+	reopened := newTournament(t, path)
+	reopened.OnResult = tour.OnResult
+
+	resumed, err := reopened.RunSwiss(context.Background(), []string{"a", "b"}, 3)
+	require.NoError(t, err)
+	assert.Zero(t, played.Load())
+	assert.Equal(t, standings, resumed)
+}
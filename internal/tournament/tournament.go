@@ -0,0 +1,109 @@
+package tournament
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/judge"
+)
+
+// Returns all ordered pairs of distinct participants, i.e. every
+// participant plays every other one both as master and as slave.
+func RoundRobin(ids []string) []Pair {
+	pairs := make([]Pair, 0, len(ids)*max(len(ids)-1, 0))
+
+	for _, master := range ids {
+		for _, slave := range ids {
+			if master != slave {
+				pairs = append(pairs, Pair{Master: master, Slave: slave})
+			}
+		}
+	}
+
+	return pairs
+}
+
+type Tournament struct {
+	Judge *judge.Judge
+
+	// Creates player factory for the participant with given id.
+	Players func(id string) game.PlayerFactory
+
+	// Each match holds 2 jobs, one per player, same as in server.
+	Jobs *semaphore.Weighted
+
+	Results *ResultLog
+
+	// Called after each match result is saved. May be called concurrently.
+	OnResult func(MatchResult)
+
+	// Called before each Swiss round is played.
+	OnRound func(Round)
+}
+
+// Returns pairs that are not yet present in the result log.
+func (t *Tournament) Pending(pairs []Pair) []Pair {
+	var pending []Pair
+
+	for _, pair := range pairs {
+		if !t.Results.Has(pair) {
+			pending = append(pending, pair)
+		}
+	}
+
+	return pending
+}
+
+// Plays all the given pairs that are not yet present in the result
+// log, saving each result as soon as it is known.
+//
+// If the context is cancelled, matches that are still running are
+// not saved, so that they will be replayed when the run is resumed.
+// Same goes for matches the judge has failed, which stop the run.
+func (t *Tournament) Run(ctx context.Context, pairs []Pair) error {
+	eg, egCtx := errgroup.WithContext(ctx)
+
+	for _, pair := range t.Pending(pairs) {
+		if err := t.Jobs.Acquire(egCtx, 2); err != nil {
+			break
+		}
+
+		eg.Go(func() error {
+			defer t.Jobs.Release(2)
+
+			verdict, _ := t.Judge.Judge(egCtx, t.Players(pair.Master), t.Players(pair.Slave))
+
+			// Verdict of an interrupted match is meaningless.
+			if egCtx.Err() != nil {
+				return context.Cause(egCtx)
+			}
+
+			// So is the verdict of a match the judge has failed, and
+			// other matches would fail the same way.
+			if verdict.Reason == judge.JudgeError {
+				return errors.New(verdict.Details)
+			}
+
+			result := MatchResult{verdict, pair}
+			if err := t.Results.Append(result); err != nil {
+				return err
+			}
+
+			if t.OnResult != nil {
+				t.OnResult(result)
+			}
+
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	return context.Cause(ctx)
+}
@@ -0,0 +1,148 @@
+package tournament_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/mrsobakin/itmournament/internal/game"
+	"github.com/mrsobakin/itmournament/internal/game/field"
+	"github.com/mrsobakin/itmournament/internal/judge"
+	"github.com/mrsobakin/itmournament/internal/tournament"
+)
+
+type crashingPlayer struct{}
+
+func (crashingPlayer) SendCommand(string) (string, error) {
+	return "", &game.ErrorTerminated{Reason: game.ReasonRuntimeError}
+}
+
+func (crashingPlayer) RetrieveField(field.Configuration) (field.Field, error) {
+	return nil, errors.New("no field")
+}
+
+func (crashingPlayer) ProvideField(field.Configuration, field.Field) error {
+	return nil
+}
+
+func (crashingPlayer) Close() error {
+	return nil
+}
+
+type crashingFactory struct{}
+
+func (crashingFactory) NewPlayer(context.Context) game.Player {
+	return crashingPlayer{}
+}
+
+func newTournament(t *testing.T, path string) *tournament.Tournament {
+	results, err := tournament.OpenResultLog(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { results.Close() })
+
+	return &tournament.Tournament{
+		Judge: &judge.Judge{
+			PlayerTimeout: time.Second,
+			GlobalTimeout: time.Second,
+		},
+		Players: func(string) game.PlayerFactory {
+			return crashingFactory{}
+		},
+		Jobs:    semaphore.NewWeighted(4),
+		Results: results,
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	pairs := tournament.RoundRobin([]string{"a", "b", "c"})
+
+	assert.ElementsMatch(t, []tournament.Pair{
+		{Master: "a", Slave: "b"},
+		{Master: "a", Slave: "c"},
+		{Master: "b", Slave: "a"},
+		{Master: "b", Slave: "c"},
+		{Master: "c", Slave: "a"},
+		{Master: "c", Slave: "b"},
+	}, pairs)
+
+	assert.Empty(t, tournament.RoundRobin([]string{"a"}))
+}
+
+func TestTournament_Run(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	tour := newTournament(t, path)
+
+	var played []tournament.MatchResult
+	tour.Jobs = semaphore.NewWeighted(2)
+	tour.OnResult = func(r tournament.MatchResult) {
+		played = append(played, r)
+	}
+
+	pairs := tournament.RoundRobin([]string{"a", "b", "c"})
+	require.NoError(t, tour.Run(context.Background(), pairs))
+
+	require.Len(t, played, len(pairs))
+	for _, r := range played {
+		assert.Equal(t, judge.SlaveWon, r.Winner)
+		assert.Equal(t, judge.RuntimeError, r.Reason)
+	}
+
+	assert.Empty(t, tour.Pending(pairs))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, len(pairs), strings.Count(string(data), "\n"))
+	assert.Contains(t, string(data), `"master_image_id":"a","slave_image_id":"b"`)
+}
+
+func TestTournament_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+
+	// Second line was cut short by a crash.
+	content := `{"winner":"master","reason":"OK","details":"","master_image_id":"a","slave_image_id":"b"}` + "\n" +
+		`{"winner":"master","reason":"OK","details":"","master_image_id":"b","sl`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	tour := newTournament(t, path)
+
+	pairs := tournament.RoundRobin([]string{"a", "b"})
+	assert.Equal(t, []tournament.Pair{{Master: "b", Slave: "a"}}, tour.Pending(pairs))
+
+	require.NoError(t, tour.Run(context.Background(), pairs))
+	assert.Empty(t, tour.Pending(pairs))
+
+	// Reopened log should see both results, ignoring the broken line.
+	reopened := newTournament(t, path)
+	assert.Empty(t, reopened.Pending(pairs))
+}
+
+func TestTournament_Cancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	tour := newTournament(t, path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pairs := tournament.RoundRobin([]string{"a", "b"})
+	assert.ErrorIs(t, tour.Run(ctx, pairs), context.Canceled)
+	assert.Len(t, tour.Pending(pairs), 2)
+}
+
+func TestTournament_JudgeError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	tour := newTournament(t, path)
+	tour.Judge.Configurations = judge.FixedConfiguration(field.Configuration{W: 10, H: 10})
+
+	// Matches the judge has failed are not results of the players.
+	pairs := tournament.RoundRobin([]string{"a", "b"})
+	assert.ErrorContains(t, tour.Run(context.Background(), pairs), "is invalid")
+	assert.Len(t, tour.Pending(pairs), 2)
+}
@@ -89,6 +89,13 @@ func (s *Stopwatch) Pause() {
 	s.totalPassed += addDuration
 }
 
+// Returns summary running time.
+//
+// Time of the current run, if stopwatch is running, is not included.
+func (s *Stopwatch) Elapsed() time.Duration {
+	return s.totalPassed
+}
+
 func (s *Stopwatch) Close() {
 	if !s.closed.Swap(true) {
 		close(s.deadlineUpdates)
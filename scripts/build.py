@@ -6,7 +6,8 @@ import httpx
 from tqdm import tqdm
 
 
-ENDPOINT = "http://localhost:4239/build"
+ENDPOINT = "http://localhost:4239/builds"
+POLL_INTERVAL = 5
 
 
 async def send_request(client: httpx.AsyncClient, bar: tqdm, semaphore: asyncio.Semaphore, repo: str, ref: str) -> dict:
@@ -15,9 +16,15 @@ async def send_request(client: httpx.AsyncClient, bar: tqdm, semaphore: asyncio.
             "repo": repo,
             "ref": ref
         })
+        job = response.json()
+
+        while job["status"] in ("queued", "running"):
+            await asyncio.sleep(POLL_INTERVAL)
+            response = await client.get(f"{ENDPOINT}/{job['id']}")
+            job = response.json()
 
         data = {
-            **response.json(),
+            **job,
             "repo": repo,
             "ref": ref,
         }